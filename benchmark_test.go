@@ -0,0 +1,123 @@
+package events_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/oddengine/events"
+	Event "github.com/oddengine/events/event"
+	"github.com/oddengine/events/reentrant"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Trace(s string)                                      {}
+func (nopLogger) Tracef(format string, args ...interface{})           {}
+func (nopLogger) Debug(n uint32, s string)                            {}
+func (nopLogger) Debugf(n uint32, format string, args ...interface{}) {}
+func (nopLogger) Info(s string)                                       {}
+func (nopLogger) Infof(format string, args ...interface{})            {}
+func (nopLogger) Warn(s string)                                       {}
+func (nopLogger) Warnf(format string, args ...interface{})            {}
+func (nopLogger) Error(s string)                                      {}
+func (nopLogger) Errorf(format string, args ...interface{})           {}
+
+// listTarget dispatches like EventTarget did with container/list, holding the lock while invoking the listeners.
+type listTarget struct {
+	mtx reentrant.Mutex
+	m   events.MappableEventListenerCollection
+}
+
+func (me *listTarget) DispatchEvent(e events.IEvent) {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	for element := me.m.List.Front(); element != nil; element = me.m.Next(element) {
+		element.Value.(*events.EventListener).Invoke(e)
+	}
+}
+
+func BenchmarkDispatchEvent(b *testing.B) {
+	for _, n := range []int{1, 10, 1000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			t := new(events.EventTarget).Init(nopLogger{})
+			for i := 0; i < n; i++ {
+				events.On(t, Event.CHANGE, func(e *Event.Event) {})
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				t.DispatchEvent(Event.New(Event.CHANGE, t))
+			}
+		})
+	}
+}
+
+func BenchmarkDispatchEventNested(b *testing.B) {
+	t := new(events.EventTarget).Init(nopLogger{})
+	events.On(t, Event.OPEN, func(e *Event.Event) {
+		// The nested dispatch looks up the goroutine ID, and finds the outer one on its stack.
+		t.DispatchEvent(Event.New(Event.CHANGE, t))
+	})
+	events.On(t, Event.CHANGE, func(e *Event.Event) {})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		t.DispatchEvent(Event.New(Event.OPEN, t))
+	}
+}
+
+func BenchmarkDispatchEventPath(b *testing.B) {
+	for _, depth := range []int{1, 4, 16} {
+		b.Run(fmt.Sprint(depth), func(b *testing.B) {
+			var parent events.IEventTargetNode
+			for i := 0; i < depth; i++ {
+				t := new(events.EventTarget).Init(nopLogger{})
+				events.On(t, Event.CHANGE, func(e *Event.Event) {}, events.EventListenerOptions{Capture: true})
+				if parent != nil {
+					t.SetParent(parent)
+				}
+				parent = t
+			}
+			t := new(events.EventTarget).Init(nopLogger{})
+			t.SetParent(parent)
+			events.On(t, Event.CHANGE, func(e *Event.Event) {})
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				t.DispatchEvent(Event.New(Event.CHANGE, t))
+			}
+		})
+	}
+}
+
+func BenchmarkDispatchParallel(b *testing.B) {
+	for _, n := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("snapshot/%d", n), func(b *testing.B) {
+			t := new(events.EventTarget).Init(nopLogger{})
+			for i := 0; i < n; i++ {
+				events.On(t, Event.CHANGE, func(e *Event.Event) {})
+			}
+
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					t.DispatchEvent(Event.New(Event.CHANGE, t))
+				}
+			})
+		})
+
+		b.Run(fmt.Sprintf("list/%d", n), func(b *testing.B) {
+			t := new(listTarget)
+			t.m.Init()
+			for i := 0; i < n; i++ {
+				t.m.Add(events.NewTypedEventListener(func(e *Event.Event) {}))
+			}
+
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					t.DispatchEvent(Event.New(Event.CHANGE, nil))
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package clock
+
+import (
+	"time"
+)
+
+// Clock tells the time, and creates the timers and tickers of the time-driven features.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer delivers a single time on its channel after the duration.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// Ticker delivers the time on its channel every period.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real is the Clock of the time package.
+type Real struct{}
+
+// Now returns the current local time.
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// NewTimer creates a new Timer with time.NewTimer.
+func (Real) NewTimer(d time.Duration) Timer {
+	return &realTimer{time.NewTimer(d)}
+}
+
+// NewTicker creates a new Ticker with time.NewTicker.
+func (Real) NewTicker(d time.Duration) Ticker {
+	return &realTicker{time.NewTicker(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (me *realTimer) C() <-chan time.Time {
+	return me.timer.C
+}
+
+func (me *realTimer) Stop() bool {
+	return me.timer.Stop()
+}
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (me *realTicker) C() <-chan time.Time {
+	return me.ticker.C
+}
+
+func (me *realTicker) Stop() {
+	me.ticker.Stop()
+}
+
+// Default returns c if it is not nil, otherwise the Real clock.
+func Default(c Clock) Clock {
+	if c == nil {
+		return Real{}
+	}
+	return c
+}
@@ -0,0 +1,156 @@
+package clocktest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/oddengine/events/clock"
+)
+
+// ManualClock is a clock.Clock for tests, which only moves when it is advanced.
+// Its timers and tickers fire during Advance, dropping the ticks which are not received in time like the time package.
+type ManualClock struct {
+	mtx     sync.Mutex
+	cond    sync.Cond
+	now     time.Time
+	waiters []*waiter
+}
+
+type waiter struct {
+	clock    *ManualClock
+	c        chan time.Time
+	deadline time.Time
+	period   time.Duration
+}
+
+// Init this class.
+func (me *ManualClock) Init(now time.Time) *ManualClock {
+	me.cond.L = &me.mtx
+	me.now = now
+	return me
+}
+
+// Now returns the current time of the clock.
+func (me *ManualClock) Now() time.Time {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	return me.now
+}
+
+// NewTimer creates a new Timer, which fires once the clock is advanced by d.
+func (me *ManualClock) NewTimer(d time.Duration) clock.Timer {
+	return me.add(d, 0)
+}
+
+// NewTicker creates a new Ticker, which fires every time the clock is advanced by d.
+func (me *ManualClock) NewTicker(d time.Duration) clock.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	return &ticker{me.add(d, d)}
+}
+
+// Advance moves the clock forward by d, and fires the timers and tickers in order of their deadlines.
+func (me *ManualClock) Advance(d time.Duration) {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	end := me.now.Add(d)
+	for {
+		w := me.next(end)
+		if w == nil {
+			break
+		}
+		me.now = w.deadline
+		select {
+		case w.c <- me.now:
+		default:
+		}
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+		} else {
+			me.remove(w)
+		}
+	}
+	me.now = end
+}
+
+// Waiters returns the number of active timers and tickers.
+func (me *ManualClock) Waiters() int {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	return len(me.waiters)
+}
+
+// BlockUntil blocks until there are n active timers and tickers, which is useful to wait for a goroutine to start.
+func (me *ManualClock) BlockUntil(n int) {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	for len(me.waiters) != n {
+		me.cond.Wait()
+	}
+}
+
+func (me *ManualClock) add(d time.Duration, period time.Duration) *waiter {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	w := &waiter{
+		clock:    me,
+		c:        make(chan time.Time, 1),
+		deadline: me.now.Add(d),
+		period:   period,
+	}
+	me.waiters = append(me.waiters, w)
+	me.cond.Broadcast()
+	return w
+}
+
+// next returns the waiter with the earliest deadline no later than end.
+func (me *ManualClock) next(end time.Time) *waiter {
+	var found *waiter
+	for _, w := range me.waiters {
+		if !w.deadline.After(end) && (found == nil || w.deadline.Before(found.deadline)) {
+			found = w
+		}
+	}
+	return found
+}
+
+func (me *ManualClock) remove(w *waiter) bool {
+	for i, v := range me.waiters {
+		if v == w {
+			me.waiters = append(me.waiters[:i], me.waiters[i+1:]...)
+			me.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+func (me *waiter) C() <-chan time.Time {
+	return me.c
+}
+
+func (me *waiter) Stop() bool {
+	me.clock.mtx.Lock()
+	defer me.clock.mtx.Unlock()
+
+	return me.clock.remove(me)
+}
+
+type ticker struct {
+	*waiter
+}
+
+func (me *ticker) Stop() {
+	me.waiter.Stop()
+}
+
+// NewManualClock creates a new ManualClock starting at the time.
+func NewManualClock(now time.Time) *ManualClock {
+	return new(ManualClock).Init(now)
+}
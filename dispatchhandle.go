@@ -0,0 +1,31 @@
+package events
+
+// DispatchHandle is returned by an asynchronous dispatch to wait on its completion.
+type DispatchHandle struct {
+	done chan struct{}
+	res  EventResult
+	err  error
+}
+
+// Init this class.
+func (me *DispatchHandle) Init() *DispatchHandle {
+	me.done = make(chan struct{})
+	return me
+}
+
+// Done returns a channel which is closed when the dispatch is completed.
+func (me *DispatchHandle) Done() <-chan struct{} {
+	return me.done
+}
+
+// Wait blocks until the dispatch is completed, and returns its result like DispatchEventWithError.
+func (me *DispatchHandle) Wait() (EventResult, error) {
+	<-me.done
+	return me.res, me.err
+}
+
+func (me *DispatchHandle) complete(res EventResult, err error) {
+	me.res = res
+	me.err = err
+	close(me.done)
+}
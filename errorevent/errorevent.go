@@ -12,6 +12,17 @@ const (
 	ERROR = "error"
 )
 
+// ErrorEvent names.
+const (
+	PANIC = "PanicError"
+)
+
+func init() {
+	events.NewPanicEvent = func(target events.IEventTarget, err *events.PanicError) events.IEvent {
+		return New(ERROR, target, PANIC, err)
+	}
+}
+
 // ErrorEvent dispatched when an error causes an asynchronous operation to fail.
 type ErrorEvent struct {
 	Event.Event
@@ -20,8 +31,8 @@ type ErrorEvent struct {
 }
 
 // Init this class.
-func (me *ErrorEvent) Init(event string, name string, message error) *ErrorEvent {
-	me.Event.Init(event)
+func (me *ErrorEvent) Init(event string, name string, message error, options ...Event.EventOptions) *ErrorEvent {
+	me.Event.Init(event, options...)
 	me.Name = name
 	me.Message = message
 	return me
@@ -29,7 +40,7 @@ func (me *ErrorEvent) Init(event string, name string, message error) *ErrorEvent
 
 // Clone an instance of an ErrorEvent subclass.
 func (me *ErrorEvent) Clone() events.IEvent {
-	return New(me.Type(), me.Target(), me.Name, me.Message)
+	return New(me.Type(), me.Target(), me.Name, me.Message, me.Options())
 }
 
 // String returns a string containing all the properties of the ErrorEvent object.
@@ -38,8 +49,8 @@ func (me *ErrorEvent) String() string {
 }
 
 // New creates a new ErrorEvent object.
-func New(typ string, target events.IEventTarget, name string, message error) *ErrorEvent {
-	e := new(ErrorEvent).Init(typ, name, message)
+func New(typ string, target events.IEventTarget, name string, message error, options ...Event.EventOptions) *ErrorEvent {
+	e := new(ErrorEvent).Init(typ, name, message, options...)
 	e.SetTarget(target)
 	e.SetCurrentTarget(target)
 	return e
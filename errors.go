@@ -0,0 +1,105 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorPolicy decides what happens when a listener returns an error.
+type ErrorPolicy int
+
+const (
+	// Every listener is invoked, and the errors are aggregated.
+	ContinueOnError ErrorPolicy = iota
+	// The first error stops the dispatch, and the default action is not executed.
+	StopOnError
+)
+
+// PanicPolicy decides what happens when a listener panics.
+type PanicPolicy int
+
+const (
+	// The target follows PANIC_POLICY.
+	DefaultPanicPolicy PanicPolicy = iota
+	// The panic is logged, and the remaining listeners are abandoned.
+	AbandonOnPanic
+	// The panic is not recovered.
+	RepanicOnPanic
+	// The panic is logged, and only the failing listener is skipped.
+	SkipOnPanic
+	// The panic is logged, and turned into an event dispatched on the same target, then only the failing listener is skipped.
+	// The event is created by NewPanicEvent, which is provided by importing the errorevent package.
+	DispatchOnPanic
+)
+
+var (
+	// PANIC_POLICY is the policy of the targets which don't set their own.
+	PANIC_POLICY = AbandonOnPanic
+	// NewPanicEvent creates the event dispatched with DispatchOnPanic, which must be a pointer, since a panic while
+	// dispatching it is recognized by comparing it.
+	NewPanicEvent func(target IEventTarget, err *PanicError) IEvent
+)
+
+// PanicError is the error recovered from a panicking listener.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// Error returns the description of the error.
+func (me *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", me.Value)
+}
+
+// Unwrap returns the recovered value if it is an error.
+func (me *PanicError) Unwrap() error {
+	if err, ok := me.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// ListenerError is the error returned by a listener while handling an event.
+type ListenerError struct {
+	Type          string
+	CurrentTarget IEventTarget
+	Listener      *EventListener
+	Err           error
+}
+
+// Error returns the description of the error.
+func (me *ListenerError) Error() string {
+	return fmt.Sprintf("listener %p failed to handle %s: %v", me.Listener, me.Type, me.Err)
+}
+
+// Unwrap returns the error returned by the listener.
+func (me *ListenerError) Unwrap() error {
+	return me.Err
+}
+
+// DispatchError aggregates the errors returned by the listeners during a dispatch, one entry per failing listener.
+type DispatchError struct {
+	Errors []*ListenerError
+}
+
+// Error returns the description of all the errors.
+func (me *DispatchError) Error() string {
+	if len(me.Errors) == 1 {
+		return me.Errors[0].Error()
+	}
+
+	msgs := make([]string, len(me.Errors))
+	for i, err := range me.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d listeners failed: %s", len(me.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors, so that errors.Is and errors.As check each of them.
+func (me *DispatchError) Unwrap() []error {
+	errs := make([]error, len(me.Errors))
+	for i, err := range me.Errors {
+		errs[i] = err
+	}
+	return errs
+}
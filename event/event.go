@@ -24,18 +24,37 @@ const (
 	REMOVED    = "removed"
 )
 
+// EventOptions specifies characteristics about the event.
+type EventOptions struct {
+	// Bubbles indicates whether the event travels back up to the root after reaching the target.
+	Bubbles bool
+	// Cancelable indicates whether the default action of the event can be prevented.
+	Cancelable bool
+}
+
 // Event is used as the base class for the creation of Event objects, which are passed as parameters to event listeners when an event occurs.
 type Event struct {
-	event              string
-	target             events.IEventTarget
-	currentTarget      events.IEventTarget
-	propagationStopped bool
+	event                       string
+	target                      events.IEventTarget
+	currentTarget               events.IEventTarget
+	eventPhase                  events.EventPhase
+	options                     EventOptions
+	defaultPrevented            bool
+	propagationStopped          bool
+	immediatePropagationStopped bool
 }
 
 // Init this class.
-func (me *Event) Init(event string) *Event {
+func (me *Event) Init(event string, options ...EventOptions) *Event {
 	me.event = event
+	me.eventPhase = events.NoPhase
+	me.options = EventOptions{}
+	if len(options) > 0 {
+		me.options = options[0]
+	}
+	me.defaultPrevented = false
 	me.propagationStopped = false
+	me.immediatePropagationStopped = false
 	return me
 }
 
@@ -69,7 +88,44 @@ func (me *Event) CurrentTarget() events.IEventTarget {
 	return me.currentTarget
 }
 
-// StopPropagation stops propagation.
+// SetEventPhase sets the current phase in the event flow.
+func (me *Event) SetEventPhase(phase events.EventPhase) {
+	me.eventPhase = phase
+}
+
+// EventPhase gets the current phase in the event flow.
+func (me *Event) EventPhase() events.EventPhase {
+	return me.eventPhase
+}
+
+// Bubbles indicates whether the event is a bubbling event.
+func (me *Event) Bubbles() bool {
+	return me.options.Bubbles
+}
+
+// Cancelable indicates whether the behavior associated with the event can be prevented.
+func (me *Event) Cancelable() bool {
+	return me.options.Cancelable
+}
+
+// Options returns the characteristics of the event, which is useful to clone it.
+func (me *Event) Options() EventOptions {
+	return me.options
+}
+
+// PreventDefault cancels the default action of the event, if it is cancelable.
+func (me *Event) PreventDefault() {
+	if me.options.Cancelable {
+		me.defaultPrevented = true
+	}
+}
+
+// DefaultPrevented returns whether the default action is canceled.
+func (me *Event) DefaultPrevented() bool {
+	return me.defaultPrevented
+}
+
+// StopPropagation stops propagation after the listeners of the current target are invoked.
 func (me *Event) StopPropagation() {
 	me.propagationStopped = true
 }
@@ -79,9 +135,20 @@ func (me *Event) PropagationStopped() bool {
 	return me.propagationStopped
 }
 
+// StopImmediatePropagation stops propagation right away, the remaining listeners of the current target are not invoked.
+func (me *Event) StopImmediatePropagation() {
+	me.propagationStopped = true
+	me.immediatePropagationStopped = true
+}
+
+// ImmediatePropagationStopped returns whether the propagation is stopped immediately.
+func (me *Event) ImmediatePropagationStopped() bool {
+	return me.immediatePropagationStopped
+}
+
 // Clone an instance of an Event subclass.
 func (me *Event) Clone() events.IEvent {
-	return New(me.Type(), me.Target())
+	return New(me.Type(), me.Target(), me.Options())
 }
 
 // String returns a string containing all the properties of the Event object.
@@ -90,8 +157,8 @@ func (me *Event) String() string {
 }
 
 // New creates a new Event object.
-func New(event string, target events.IEventTarget) *Event {
-	e := new(Event).Init(event)
+func New(event string, target events.IEventTarget, options ...EventOptions) *Event {
+	e := new(Event).Init(event, options...)
 	e.SetTarget(target)
 	e.SetCurrentTarget(target)
 	return e
@@ -2,34 +2,87 @@ package events
 
 import (
 	"container/list"
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
+	"sync/atomic"
 	"unsafe"
 )
 
+var (
+	// ErrInvalidHandler is returned if a handler is not a func(T) or func(T) error, where T implements IEvent.
+	ErrInvalidHandler = errors.New("invalid event handler")
+
+	eventType = reflect.TypeOf((*IEvent)(nil)).Elem()
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
 // EventListenerOptions specifies characteristics about the event listener.
 type EventListenerOptions struct {
 	Once bool
+	// Capture makes the listener work in the capture phase, it will not be invoked on its own target.
+	Capture bool
+	// Priority of the listener, the higher the earlier it is invoked. Listeners with equal priority are invoked in the order they were added.
+	Priority int
 }
 
 // EventListener holds the event handler.
 type EventListener struct {
 	handler interface{}
+	invoke  func(e IEvent) error
+	accept  func(e IEvent) bool
 	options EventListenerOptions
 }
 
 // Init this class.
+// It panics with ErrInvalidHandler if the handler doesn't pass ValidateHandler.
 func (me *EventListener) Init(handler interface{}, options ...EventListenerOptions) *EventListener {
+	if err := ValidateHandler(handler); err != nil {
+		panic(err)
+	}
+
 	me.handler = handler
+	switch fn := handler.(type) {
+	case func(IEvent):
+		me.invoke = func(e IEvent) error {
+			fn(e)
+			return nil
+		}
+	case func(IEvent) error:
+		me.invoke = fn
+	default:
+		param := reflect.TypeOf(handler).In(0)
+		me.accept = func(e IEvent) bool {
+			return reflect.TypeOf(e).AssignableTo(param)
+		}
+		me.invoke = func(e IEvent) error {
+			if !me.accept(e) {
+				return nil
+			}
+			value := reflect.ValueOf(e)
+			out := reflect.ValueOf(me.handler).Call([]reflect.Value{value})
+			if len(out) == 0 || out[0].IsNil() {
+				return nil
+			}
+			return out[0].Interface().(error)
+		}
+	}
 	if len(options) > 0 {
 		me.options = options[0]
 	}
 	return me
 }
 
-// Invoke calls handler with the IEvent.
-func (me *EventListener) Invoke(e IEvent) {
-	value := reflect.ValueOf(e)
-	reflect.ValueOf(me.handler).Call([]reflect.Value{value})
+// Invoke calls handler with the IEvent, and returns the error returned by the handler, if any.
+func (me *EventListener) Invoke(e IEvent) error {
+	return me.invoke(e)
+}
+
+// accepts returns whether the handler takes the event. An event which is not accepted is not delivered to the
+// handler, and does not use up a Once listener.
+func (me *EventListener) accepts(e IEvent) bool {
+	return me.accept == nil || me.accept(e)
 }
 
 // Matches returns whether or not it is equal to the argument.
@@ -38,6 +91,8 @@ func (me *EventListener) Matches(listener *EventListener) bool {
 }
 
 // MappableEventListenerCollection is a mappable listener collection.
+//
+// Deprecated: EventTarget keeps copy-on-write snapshots of its listeners instead, so that dispatching doesn't hold the lock.
 type MappableEventListenerCollection struct {
 	List     list.List
 	elements map[uintptr]*list.Element
@@ -52,12 +107,23 @@ func (me *MappableEventListenerCollection) Init() *MappableEventListenerCollecti
 	return me
 }
 
-// Add adds the listener into collection.
+// Add adds the listener into collection, after the listeners with equal or higher priority.
 func (me *MappableEventListenerCollection) Add(listener *EventListener) {
 	key := uintptr(unsafe.Pointer(listener))
-	if _, ok := me.elements[key]; !ok {
-		me.elements[key] = me.List.PushBack(listener)
+	if _, ok := me.elements[key]; ok {
+		// Re-adding a listener which is pending removal keeps it in place.
 		delete(me.removed, key)
+	} else {
+		// Find the last element with equal or higher priority, which is usually the back.
+		mark := me.List.Back()
+		for mark != nil && mark.Value.(*EventListener).options.Priority < listener.options.Priority {
+			mark = mark.Prev()
+		}
+		if mark == nil {
+			me.elements[key] = me.List.PushFront(listener)
+		} else {
+			me.elements[key] = me.List.InsertAfter(listener, mark)
+		}
 	}
 }
 
@@ -101,7 +167,177 @@ func (me *MappableEventListenerCollection) Len() int {
 	return me.List.Len()
 }
 
+// ValidateHandler checks whether the handler takes exactly one parameter which implements IEvent, and returns
+// either nothing or an error.
+func ValidateHandler(handler interface{}) error {
+	if handler == nil {
+		return fmt.Errorf("%w: nil", ErrInvalidHandler)
+	}
+
+	typ := reflect.TypeOf(handler)
+	if typ.Kind() != reflect.Func {
+		return fmt.Errorf("%w: %v is not a function", ErrInvalidHandler, typ)
+	}
+	if reflect.ValueOf(handler).IsNil() {
+		return fmt.Errorf("%w: nil %v", ErrInvalidHandler, typ)
+	}
+	if typ.NumIn() != 1 || typ.IsVariadic() {
+		return fmt.Errorf("%w: %v must take exactly one parameter", ErrInvalidHandler, typ)
+	}
+	if !typ.In(0).Implements(eventType) {
+		return fmt.Errorf("%w: parameter of %v does not implement events.IEvent", ErrInvalidHandler, typ)
+	}
+	if typ.NumOut() > 1 || (typ.NumOut() == 1 && typ.Out(0) != errorType) {
+		return fmt.Errorf("%w: %v must return nothing or an error", ErrInvalidHandler, typ)
+	}
+	return nil
+}
+
+// eventListenerEntry is the registration of a listener for an event type or pattern.
+type eventListenerEntry struct {
+	event    string
+	listener *EventListener
+	seq      uint64
+	removed  atomic.Bool
+}
+
+// eventListenerIndex maps the event types and patterns to immutable snapshots of their listeners.
+// A pattern is either "*", which matches every type, or a dotted namespace ending with ".*", which matches the types
+// in the namespace, e.g. "NetStream.Play.*". Patterns are keyed by their prefix, so that matching a type only looks
+// up its dotted prefixes.
+type eventListenerIndex struct {
+	types    map[string][]*eventListenerEntry
+	patterns map[string][]*eventListenerEntry
+}
+
+func (me *eventListenerIndex) key(event string) (map[string][]*eventListenerEntry, string) {
+	if event == "*" {
+		return me.patterns, ""
+	}
+	if strings.HasSuffix(event, ".*") {
+		return me.patterns, event[:len(event)-1]
+	}
+	return me.types, event
+}
+
+// get returns the listeners of the type or pattern.
+func (me *eventListenerIndex) get(event string) []*eventListenerEntry {
+	entries, key := me.key(event)
+	return entries[key]
+}
+
+// set replaces the listeners of the type or pattern, on copies of the maps so that the snapshots stay immutable.
+func (me *eventListenerIndex) set(event string, entries []*eventListenerEntry) {
+	src, key := me.key(event)
+	dst := make(map[string][]*eventListenerEntry, len(src)+1)
+	for k, v := range src {
+		dst[k] = v
+	}
+	if len(entries) == 0 {
+		delete(dst, key)
+	} else {
+		dst[key] = entries
+	}
+
+	if event == "*" || strings.HasSuffix(event, ".*") {
+		me.patterns = dst
+	} else {
+		me.types = dst
+	}
+}
+
+// match appends the listeners matching the type to dst, from the exact type to the least specific pattern.
+func (me *eventListenerIndex) match(event string, dst [][]*eventListenerEntry) [][]*eventListenerEntry {
+	if entries := me.types[event]; entries != nil {
+		dst = append(dst, entries)
+	}
+	if len(me.patterns) == 0 {
+		return dst
+	}
+	for i := len(event) - 1; i > 0; i-- {
+		if event[i] == '.' {
+			if entries := me.patterns[event[:i+1]]; entries != nil {
+				dst = append(dst, entries)
+			}
+		}
+	}
+	if entries := me.patterns[""]; entries != nil {
+		dst = append(dst, entries)
+	}
+	return dst
+}
+
+// insertEntry returns a copy of entries with the entry after the ones with equal or higher priority.
+func insertEntry(entries []*eventListenerEntry, entry *eventListenerEntry) []*eventListenerEntry {
+	i := len(entries)
+	for i > 0 && entries[i-1].listener.options.Priority < entry.listener.options.Priority {
+		i--
+	}
+
+	dst := make([]*eventListenerEntry, 0, len(entries)+1)
+	dst = append(dst, entries[:i]...)
+	dst = append(dst, entry)
+	return append(dst, entries[i:]...)
+}
+
+// removeEntry returns a copy of entries without the one at i.
+func removeEntry(entries []*eventListenerEntry, i int) []*eventListenerEntry {
+	dst := make([]*eventListenerEntry, 0, len(entries)-1)
+	dst = append(dst, entries[:i]...)
+	return append(dst, entries[i+1:]...)
+}
+
 // NewEventListener returns new EventListener.
+// The handler is called through reflection, unless it is a func(IEvent). Prefer NewTypedEventListener.
+// It panics with ErrInvalidHandler if the handler doesn't pass ValidateHandler.
 func NewEventListener(handler interface{}, options ...EventListenerOptions) *EventListener {
 	return new(EventListener).Init(handler, options...)
 }
+
+// NewTypedEventListener returns new EventListener, which calls the handler directly.
+// Events which are not of type T are not delivered to the handler.
+func NewTypedEventListener[T IEvent](handler func(T), options ...EventListenerOptions) *EventListener {
+	me := new(EventListener).Init(handler, options...)
+	me.accept = func(e IEvent) bool {
+		_, ok := e.(T)
+		return ok
+	}
+	me.invoke = func(e IEvent) error {
+		if t, ok := e.(T); ok {
+			handler(t)
+		}
+		return nil
+	}
+	return me
+}
+
+// NewTypedEventListenerWithError returns new EventListener, which calls the handler directly and reports its error.
+// Events which are not of type T are not delivered to the handler.
+func NewTypedEventListenerWithError[T IEvent](handler func(T) error, options ...EventListenerOptions) *EventListener {
+	me := new(EventListener).Init(handler, options...)
+	me.accept = func(e IEvent) bool {
+		_, ok := e.(T)
+		return ok
+	}
+	me.invoke = func(e IEvent) error {
+		if t, ok := e.(T); ok {
+			return handler(t)
+		}
+		return nil
+	}
+	return me
+}
+
+// On registers the handler with the target for the event type, and returns the EventListener to remove it later.
+func On[T IEvent](target IEventTarget, event string, handler func(T), options ...EventListenerOptions) *EventListener {
+	listener := NewTypedEventListener(handler, options...)
+	target.AddEventListener(event, listener)
+	return listener
+}
+
+// OnWithError registers the handler which may fail with the target for the event type, and returns the EventListener to remove it later.
+func OnWithError[T IEvent](target IEventTarget, event string, handler func(T) error, options ...EventListenerOptions) *EventListener {
+	listener := NewTypedEventListenerWithError(handler, options...)
+	target.AddEventListener(event, listener)
+	return listener
+}
@@ -0,0 +1,91 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/oddengine/events"
+	Event "github.com/oddengine/events/event"
+)
+
+func TestMappableEventListenerCollectionDeferredRemoval(t *testing.T) {
+	var m events.MappableEventListenerCollection
+	m.Init()
+
+	listeners := make([]*events.EventListener, 3)
+	for i := range listeners {
+		listeners[i] = events.NewTypedEventListener(func(e *Event.Event) {})
+		m.Add(listeners[i])
+	}
+
+	m.Remove(listeners[1], false)
+	if m.Len() != 3 {
+		t.Fatalf("len = %d, want 3 before RemoveEventually", m.Len())
+	}
+
+	front := m.List.Front()
+	if next := m.Next(front); next == nil || next.Value != listeners[2] {
+		t.Fatalf("next of front should skip the removed listener")
+	}
+
+	m.RemoveEventually()
+	if m.Len() != 2 {
+		t.Fatalf("len = %d, want 2 after RemoveEventually", m.Len())
+	}
+
+	m.Remove(listeners[0], true)
+	if m.Len() != 1 || m.List.Front().Value != listeners[2] {
+		t.Fatalf("listener not removed immediately")
+	}
+}
+
+func TestMappableEventListenerCollectionReAdd(t *testing.T) {
+	var m events.MappableEventListenerCollection
+	m.Init()
+
+	l := events.NewTypedEventListener(func(e *Event.Event) {})
+	m.Add(l)
+	m.Remove(l, false)
+	m.Add(l)
+	m.RemoveEventually()
+	if m.Len() != 1 {
+		t.Fatalf("len = %d, want 1", m.Len())
+	}
+}
+
+func TestValidateHandler(t *testing.T) {
+	for _, handler := range []interface{}{
+		nil,
+		42,
+		func() {},
+		func(a, b *Event.Event) {},
+		func(s string) {},
+		func(e *Event.Event) int { return 0 },
+		func(e *Event.Event) (error, error) { return nil, nil },
+		(func(e *Event.Event))(nil),
+	} {
+		if err := events.ValidateHandler(handler); !errors.Is(err, events.ErrInvalidHandler) {
+			t.Errorf("handler %T accepted", handler)
+		}
+	}
+
+	for _, handler := range []interface{}{
+		func(e events.IEvent) {},
+		func(e *Event.Event) {},
+		func(e *Event.Event) error { return nil },
+	} {
+		if err := events.ValidateHandler(handler); err != nil {
+			t.Errorf("handler %T rejected: %v", handler, err)
+		}
+	}
+}
+
+func TestNewEventListenerPanics(t *testing.T) {
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, events.ErrInvalidHandler) {
+			t.Fatalf("unexpected panic: %v", err)
+		}
+	}()
+
+	events.NewEventListener(func() {})
+}
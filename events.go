@@ -13,6 +13,20 @@ const (
 	CanceledByDefaultEventHandler
 )
 
+// EventPhase is the current phase in the event flow.
+type EventPhase int
+
+const (
+	// Event is not being dispatched.
+	NoPhase EventPhase = iota
+	// Event is travelling from the root down to the parent of the target.
+	CapturingPhase
+	// Event is being processed by the listeners of the target.
+	AtTarget
+	// Event is travelling from the parent of the target back up to the root.
+	BubblingPhase
+)
+
 // IEvent defines basic event methods.
 type IEvent interface {
 	SetType(event string)
@@ -21,12 +35,26 @@ type IEvent interface {
 	Target() IEventTarget
 	SetCurrentTarget(target IEventTarget)
 	CurrentTarget() IEventTarget
+	SetEventPhase(phase EventPhase)
+	EventPhase() EventPhase
+	Bubbles() bool
+	Cancelable() bool
+	PreventDefault()
+	DefaultPrevented() bool
 	StopPropagation()
 	PropagationStopped() bool
+	StopImmediatePropagation()
+	ImmediatePropagationStopped() bool
 	Clone() IEvent
 	String() string
 }
 
+// ISubTypedEvent is an IEvent which is also delivered to the listeners of its sub-type, after the ones of its type.
+type ISubTypedEvent interface {
+	IEvent
+	SubType() string
+}
+
 // IEventTarget objects allow us to add and remove an event listeners of a specific event type.
 // Each IEventTarget object also represents the target to which an event is dispatched when something has occurred.
 type IEventTarget interface {
@@ -34,3 +62,12 @@ type IEventTarget interface {
 	RemoveEventListener(event string, listener *EventListener)
 	DispatchEvent(e IEvent) EventResult
 }
+
+// IEventTargetNode is an IEventTarget linked to a parent, so that events dispatched on its descendants travel
+// through it in the capture and bubble phases.
+type IEventTargetNode interface {
+	IEventTarget
+	SetParent(parent IEventTargetNode)
+	Parent() IEventTargetNode
+	InvokeEventListeners(e IEvent, policy ErrorPolicy) (EventResult, []*ListenerError)
+}
@@ -2,7 +2,11 @@ package events
 
 import (
 	"fmt"
+	"reflect"
+	"runtime"
 	"runtime/debug"
+	"sync"
+	"sync/atomic"
 
 	"github.com/oddengine/events/reentrant"
 	"github.com/oddengine/log"
@@ -14,24 +18,122 @@ const (
 )
 
 // EventTarget is the base class for all classes that dispatch events.
-// It uses array instead of list, which causes the add/remove method expensive.
-// However, it is possible to clone the listener group fast while triggering an event.
+// It publishes an immutable snapshot of arrays on every add/remove, which causes the add/remove method expensive.
+// However, triggering an event iterates the snapshot without holding the lock.
 // And, the frequency of triggering event is much higher than that of add/remove.
 type EventTarget struct {
-	mtx       reentrant.Mutex
-	logger    log.ILogger
-	listeners map[string]*MappableEventListenerCollection
-	recursion int32
+	mtx         sync.Mutex
+	logger      log.ILogger
+	parent      atomic.Pointer[eventTargetParent]
+	table       atomic.Pointer[eventListenerTable]
+	errorPolicy atomic.Int32
+	panicPolicy atomic.Int32
+
+	panicMtx    sync.Mutex
+	panicEvents []IEvent
+
+	depthMtx       sync.Mutex
+	anonymous      bool
+	anonymousOwner int64
+	depths         map[int64]int32
+
+	loop atomic.Pointer[Loop]
+
+	asyncMtx     sync.Mutex
+	workerPool   *WorkerPool
+	asyncQueue   []func()
+	asyncRunning bool
+}
+
+// eventTargetParent boxes the parent, so that it can be stored atomically.
+type eventTargetParent struct {
+	node IEventTargetNode
+}
+
+// eventListenerTable is an immutable snapshot of the listeners and default actions of an EventTarget.
+type eventListenerTable struct {
+	seq              uint64
+	listeners        eventListenerIndex
+	captureListeners eventListenerIndex
+	defaultActions   map[string]func(e IEvent)
+}
+
+func (me *eventListenerTable) index(useCapture bool) *eventListenerIndex {
+	if useCapture {
+		return &me.captureListeners
+	}
+	return &me.listeners
 }
 
 // Init this class.
 func (me *EventTarget) Init(logger log.ILogger) *EventTarget {
 	me.logger = logger
-	me.listeners = make(map[string]*MappableEventListenerCollection)
+	me.table.Store(new(eventListenerTable))
 	return me
 }
 
+// SetParent links this target to the parent, which receives the events dispatched on this target in the capture and bubble phases.
+func (me *EventTarget) SetParent(parent IEventTargetNode) {
+	me.parent.Store(&eventTargetParent{parent})
+}
+
+// Parent returns the parent target, or nil if this is a root target.
+func (me *EventTarget) Parent() IEventTargetNode {
+	if p := me.parent.Load(); p != nil {
+		return p.node
+	}
+	return nil
+}
+
+// SetErrorPolicy sets what happens when a listener returns an error while dispatching on this target.
+func (me *EventTarget) SetErrorPolicy(policy ErrorPolicy) {
+	me.errorPolicy.Store(int32(policy))
+}
+
+// SetPanicPolicy sets what happens when a listener of this target panics.
+// DefaultPanicPolicy makes this target follow PANIC_POLICY.
+func (me *EventTarget) SetPanicPolicy(policy PanicPolicy) {
+	me.panicPolicy.Store(int32(policy))
+}
+
+// PanicPolicy returns what happens when a listener of this target panics.
+func (me *EventTarget) PanicPolicy() PanicPolicy {
+	if policy := PanicPolicy(me.panicPolicy.Load()); policy != DefaultPanicPolicy {
+		return policy
+	}
+	return PANIC_POLICY
+}
+
+// SetWorkerPool sets the pool which runs the asynchronous dispatches of this target.
+// A nil pool makes this target use DefaultWorkerPool.
+func (me *EventTarget) SetWorkerPool(pool *WorkerPool) {
+	me.asyncMtx.Lock()
+	defer me.asyncMtx.Unlock()
+
+	me.workerPool = pool
+}
+
+// SetLoop binds this target to the loop, which runs all of its dispatches. A dispatch from another goroutine is
+// posted to the loop, and DispatchEvent waits for it, so a task of the loop must not wait for such a dispatch.
+// A nil loop unbinds this target.
+func (me *EventTarget) SetLoop(loop *Loop) {
+	me.loop.Store(loop)
+}
+
+// Loop returns the loop this target is bound to, or nil.
+func (me *EventTarget) Loop() *Loop {
+	return me.loop.Load()
+}
+
+func (me *EventTarget) snapshot() *eventListenerTable {
+	if t := me.table.Load(); t != nil {
+		return t
+	}
+	return new(eventListenerTable)
+}
+
 // AddEventListener registers an event listener object with an EventTarget object so that the listener receives notification of an event.
+// The event may also be a pattern: "*" matches every type, and a dotted namespace ending with ".*" matches the types in it.
 func (me *EventTarget) AddEventListener(event string, listener *EventListener) {
 	if event == "" || listener == nil {
 		me.logger.Debugf(1, "Event type or listener not present: type=%s, listener=%p", event, listener)
@@ -41,14 +143,19 @@ func (me *EventTarget) AddEventListener(event string, listener *EventListener) {
 	me.mtx.Lock()
 	defer me.mtx.Unlock()
 
-	m := me.listeners[event]
-	if m == nil {
-		m = new(MappableEventListenerCollection).Init()
-		me.listeners[event] = m
+	t := *me.snapshot()
+	index := t.index(listener.options.Capture)
+	entries := index.get(event)
+	for _, entry := range entries {
+		if entry.listener == listener {
+			return
+		}
 	}
 
 	me.logger.Debugf(1, "Adding event listener: type=%s, listener=%p", event, listener)
-	m.Add(listener)
+	t.seq++
+	index.set(event, insertEntry(entries, &eventListenerEntry{event: event, listener: listener, seq: t.seq}))
+	me.table.Store(&t)
 }
 
 // RemoveEventListener removes an event listener from the EventTarget object.
@@ -61,65 +168,613 @@ func (me *EventTarget) RemoveEventListener(event string, listener *EventListener
 	me.mtx.Lock()
 	defer me.mtx.Unlock()
 
-	m := me.listeners[event]
-	if m == nil {
-		me.logger.Debugf(0, "No listener[s] found: type=%s", event)
+	t := *me.snapshot()
+	index := t.index(listener.options.Capture)
+	entries := index.get(event)
+	for i, entry := range entries {
+		if entry.listener == listener {
+			me.logger.Debugf(1, "Removing event listener: type=%s, listener=%p", event, listener)
+			entry.removed.Store(true)
+			index.set(event, removeEntry(entries, i))
+			me.table.Store(&t)
+			return
+		}
+	}
+	me.logger.Debugf(0, "No listener[s] found: type=%s", event)
+}
+
+// removeOnce removes the registration of a listener with the Once option, which is already marked as removed.
+func (me *EventTarget) removeOnce(entry *eventListenerEntry) {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	t := *me.snapshot()
+	index := t.index(entry.listener.options.Capture)
+	entries := index.get(entry.event)
+	for i, v := range entries {
+		if v == entry {
+			me.logger.Debugf(1, "Removing event listener: type=%s, listener=%p", entry.event, entry.listener)
+			index.set(entry.event, removeEntry(entries, i))
+			me.table.Store(&t)
+			return
+		}
+	}
+}
+
+// SetDefaultAction registers the action executed after the listeners of an event type dispatched on this target,
+// unless one of them calls PreventDefault on a cancelable event. A nil action removes the registered one.
+func (me *EventTarget) SetDefaultAction(event string, action func(e IEvent)) {
+	if event == "" {
+		me.logger.Debugf(1, "Event type not present: action=%p", action)
 		return
 	}
 
-	me.logger.Debugf(1, "Removing event listener: type=%s, listener=%p", event, listener)
-	m.Remove(listener, me.recursion == 0)
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	t := *me.snapshot()
+	actions := make(map[string]func(e IEvent), len(t.defaultActions)+1)
+	for k, v := range t.defaultActions {
+		actions[k] = v
+	}
+	if action == nil {
+		delete(actions, event)
+	} else {
+		actions[event] = action
+	}
+	t.defaultActions = actions
+	me.table.Store(&t)
 }
 
 // DispatchEvent dispatches an event into the event flow.
+// The event travels from the root down to the parent of this target in the capture phase, reaches this target,
+// and then travels back up to the root in the bubble phase if it bubbles. At last, the default action of the
+// event type is executed, unless a listener prevented it. Errors returned by the listeners are logged, and so are
+// recovered panics, once, where they are recovered.
 func (me *EventTarget) DispatchEvent(e IEvent) EventResult {
+	res, err := me.DispatchEventWithError(e)
+	if err = withoutPanics(err); err != nil {
+		me.logger.Errorf("Failed to handle event: type=%s, %v", e.Type(), err)
+	}
+	return res
+}
+
+// withoutPanics drops the recovered panics from the error, which were logged with their stack when recovered.
+func withoutPanics(err error) error {
+	switch v := err.(type) {
+	case *PanicError:
+		return nil
+	case *DispatchError:
+		errs := make([]*ListenerError, 0, len(v.Errors))
+		for _, e := range v.Errors {
+			if _, ok := e.Err.(*PanicError); !ok {
+				errs = append(errs, e)
+			}
+		}
+		if len(errs) == 0 {
+			return nil
+		}
+		if len(errs) < len(v.Errors) {
+			return &DispatchError{Errors: errs}
+		}
+	}
+	return err
+}
+
+// DispatchEventWithError dispatches an event into the event flow like DispatchEvent, and returns a *DispatchError
+// holding the errors returned by the listeners, if any. A panic which abandons the dispatch is returned as a *PanicError,
+// unless the panic policy of this target is RepanicOnPanic. If this target is bound to a loop, the dispatch runs on it,
+// and ErrLoopClosed is returned if the loop is closed.
+func (me *EventTarget) DispatchEventWithError(e IEvent) (res EventResult, err error) {
+	if loop := me.loop.Load(); loop != nil && !loop.InLoop() {
+		return me.dispatchOnLoop(loop, e)
+	}
+
+	panicPolicy := me.PanicPolicy()
 	defer func() {
-		if err := recover(); err != nil {
-			me.logger.Errorf("Failed to handle event: type=%s, %v", e.Type(), err)
-			debug.PrintStack()
+		if panicPolicy == RepanicOnPanic {
+			return
+		}
+		if x := recover(); x != nil {
+			perr := &PanicError{Value: x, Stack: debug.Stack()}
+			me.handlePanic(e.Target(), e, perr, panicPolicy)
+			err = perr
 		}
 	}()
 
-	me.mtx.Lock()
-	defer me.mtx.Unlock()
+	// Check recursion.
+	goid, recursion := me.enter()
+	defer me.leave(goid)
 
-	e.SetCurrentTarget(me)
+	if MAX_RECURSION > 0 && recursion > MAX_RECURSION {
+		panic(fmt.Sprintf("max recursion reached: %d", recursion))
+	}
+	if goid == 0 {
+		return dispatchAnonymously(me, e)
+	}
+	return me.dispatch(e)
+}
+
+// dispatchOnLoop posts the dispatch to the loop, and waits for it. A panic which escapes the dispatch, e.g. with
+// RepanicOnPanic, is raised again on the calling goroutine.
+func (me *EventTarget) dispatchOnLoop(loop *Loop, e IEvent) (res EventResult, err error) {
+	var x interface{}
+	done := make(chan struct{})
+	task := func() {
+		defer func() {
+			x = recover()
+			close(done)
+		}()
+
+		res, err = me.DispatchEventWithError(e)
+	}
+	if !loop.Post(task) {
+		return NotCanceled, ErrLoopClosed
+	}
+
+	<-done
+	if x != nil {
+		panic(x)
+	}
+	return res, err
+}
+
+// dispatchAnonymously runs the dispatch which didn't look up its goroutine ID, so that a nested dispatch finds this
+// frame on its stack.
+//
+//go:noinline
+func dispatchAnonymously(me *EventTarget, e IEvent) (EventResult, error) {
+	return me.dispatch(e)
+}
+
+func (me *EventTarget) dispatch(e IEvent) (EventResult, error) {
+	var err error
+
+	policy := ErrorPolicy(me.errorPolicy.Load())
 	me.logger.Debugf(0, "Dispatching event: %s", e.Type())
 
-	// Check recursion.
-	me.recursion++
-	defer func() {
-		me.recursion--
-	}()
+	res, errs := me.propagate(e, policy)
+	if len(errs) > 0 {
+		err = &DispatchError{Errors: errs}
+		if policy == StopOnError {
+			return CanceledByEventHandler, err
+		}
+	}
+	if e.DefaultPrevented() {
+		me.logger.Debugf(1, "Default prevented: type=%s", e.Type())
+		return CanceledByEventHandler, err
+	}
+	if me.invokeDefaultAction(e) {
+		return CanceledByDefaultEventHandler, err
+	}
+	return res, err
+}
+
+// DispatchEventAsync dispatches an event like DispatchEventWithError on the loop this target is bound to, or on the
+// worker pool, and returns immediately. The asynchronous dispatches of this target are executed one by one, in the
+// order they were requested. A panic which escapes the dispatch completes the handle with a *PanicError.
+func (me *EventTarget) DispatchEventAsync(e IEvent) *DispatchHandle {
+	handle := new(DispatchHandle).Init()
+	job := func() {
+		var (
+			res EventResult
+			err error
+		)
+		// A panic which is not recovered by the dispatch, e.g. with RepanicOnPanic, must not stop the queue.
+		defer func() {
+			if x := recover(); x != nil {
+				perr := &PanicError{Value: x, Stack: debug.Stack()}
+				me.logger.Errorf("Recovered from panic of asynchronous dispatch: type=%s, %v\n%s", e.Type(), x, perr.Stack)
+				res, err = NotCanceled, perr
+			}
+			handle.complete(res, err)
+		}()
+
+		res, err = me.DispatchEventWithError(e)
+	}
+
+	me.asyncMtx.Lock()
+	defer me.asyncMtx.Unlock()
+
+	if loop := me.loop.Load(); loop != nil {
+		if !loop.Post(job) {
+			handle.complete(NotCanceled, ErrLoopClosed)
+		}
+		return handle
+	}
+
+	me.asyncQueue = append(me.asyncQueue, job)
+	if me.asyncRunning {
+		return handle
+	}
+
+	pool := me.workerPool
+	if pool == nil {
+		pool = DefaultWorkerPool()
+	}
+	if !pool.Submit(me.drainAsyncQueue) {
+		me.asyncQueue = me.asyncQueue[:len(me.asyncQueue)-1]
+		handle.complete(NotCanceled, ErrWorkerPoolClosed)
+		return handle
+	}
+	me.asyncRunning = true
+	return handle
+}
+
+// drainAsyncQueue runs on a worker until the asynchronous dispatches of this target are done.
+func (me *EventTarget) drainAsyncQueue() {
+	for {
+		me.asyncMtx.Lock()
+		if len(me.asyncQueue) == 0 {
+			me.asyncRunning = false
+			me.asyncMtx.Unlock()
+			return
+		}
+		job := me.asyncQueue[0]
+		me.asyncQueue[0] = nil
+		me.asyncQueue = me.asyncQueue[1:]
+		me.asyncMtx.Unlock()
 
-	if MAX_RECURSION > 0 && me.recursion > MAX_RECURSION {
-		panic(fmt.Sprintf("max recursion reached: %d", me.recursion))
+		job()
 	}
+}
+
+func (me *EventTarget) propagate(e IEvent, policy ErrorPolicy) (EventResult, []*ListenerError) {
+	var errs []*ListenerError
+
+	path := me.propagationPath()
+	defer e.SetEventPhase(NoPhase)
+
+	// Capture phase.
+	e.SetEventPhase(CapturingPhase)
+	for i := len(path) - 1; i >= 0; i-- {
+		e.SetCurrentTarget(path[i])
+		res, failed := path[i].InvokeEventListeners(e, policy)
+		errs = append(errs, failed...)
+		if res != NotCanceled {
+			return res, errs
+		}
+	}
+
+	// At target.
+	e.SetEventPhase(AtTarget)
+	e.SetCurrentTarget(me.self(e))
+	res, failed := me.InvokeEventListeners(e, policy)
+	errs = append(errs, failed...)
+	if res != NotCanceled {
+		return res, errs
+	}
+
+	// Bubble phase.
+	if !e.Bubbles() {
+		return NotCanceled, errs
+	}
+	e.SetEventPhase(BubblingPhase)
+	for _, node := range path {
+		e.SetCurrentTarget(node)
+		res, failed := node.InvokeEventListeners(e, policy)
+		errs = append(errs, failed...)
+		if res != NotCanceled {
+			return res, errs
+		}
+	}
+	return NotCanceled, errs
+}
+
+// self returns the target of the event if it embeds this EventTarget, so that the current target at target is the
+// same object as the parents on the propagation path. Otherwise, it returns this EventTarget.
+func (me *EventTarget) self(e IEvent) IEventTarget {
+	if t, ok := e.Target().(interface{ eventTarget() *EventTarget }); ok && t.eventTarget() == me {
+		return e.Target()
+	}
+	return me
+}
+
+// eventTarget is promoted to the types embedding an EventTarget.
+func (me *EventTarget) eventTarget() *EventTarget {
+	return me
+}
+
+func (me *EventTarget) invokeDefaultAction(e IEvent) bool {
+	action := me.snapshot().defaultActions[e.Type()]
+	if action == nil {
+		return false
+	}
+
+	e.SetEventPhase(AtTarget)
+	e.SetCurrentTarget(me.self(e))
+	defer e.SetEventPhase(NoPhase)
+
+	me.logger.Debugf(1, "Executing default action: type=%s", e.Type())
+	action(e)
+	return true
+}
+
+// InvokeEventListeners invokes the listeners of this target with the event, without propagating it.
+// The capture listeners are invoked in the capture phase, otherwise the others. The listeners of the exact type, of
+// the matching patterns, and of the sub-type of an ISubTypedEvent are invoked together in the order of priority, and
+// in the order they were added for equal priorities.
+// It returns the errors returned by the listeners, and stops at the first one if the policy is StopOnError.
+func (me *EventTarget) InvokeEventListeners(e IEvent, policy ErrorPolicy) (EventResult, []*ListenerError) {
+	var errs []*ListenerError
 
-	// Get the typed listener collection.
-	m := me.listeners[e.Type()]
-	if m == nil {
+	if e.PropagationStopped() {
+		return CanceledByEventHandler, nil
+	}
+
+	// Get the listeners of the type, followed by the matching patterns, and then the ones of the sub-type.
+	var buf, subBuf [4][]*eventListenerEntry
+	index := me.snapshot().index(e.EventPhase() == CapturingPhase)
+	ms := index.match(e.Type(), buf[:0])
+	if se, ok := e.(ISubTypedEvent); ok {
+		if sub := se.SubType(); sub != "" && sub != e.Type() {
+			ms = appendUnique(ms, index.match(sub, subBuf[:0]))
+		}
+	}
+	if len(ms) == 0 {
 		me.logger.Debugf(0, "No listener[s] found: type=%s", e.Type())
-		return NotCanceled
+		return NotCanceled, nil
 	}
 
-	if me.recursion == 1 {
-		defer m.RemoveEventually()
+	// Loop to invoke the handlers in the order of priority across the matches, skipping the ones removed after the
+	// snapshot was taken.
+	var posBuf [4]int
+	pos := posBuf[:0]
+	for range ms {
+		pos = append(pos, 0)
 	}
+	for {
+		i := nextEntry(ms, pos)
+		if i < 0 {
+			break
+		}
+		entry := ms[i][pos[i]]
+		pos[i]++
 
-	// Loop to invoke the handlers.
-	for element := m.List.Front(); element != nil; element = m.Next(element) {
-		listener := element.Value.(*EventListener)
-		listener.Invoke(e)
+		if entry.removed.Load() {
+			continue
+		}
 
+		listener := entry.listener
+		if !listener.accepts(e) {
+			continue
+		}
 		if listener.options.Once {
-			me.logger.Debugf(1, "Removing event listener: type=%s, listener=%p", e.Type(), listener)
-			m.Remove(listener, me.recursion == 0)
+			if !entry.removed.CompareAndSwap(false, true) {
+				continue
+			}
+			me.removeOnce(entry)
+		}
+
+		err := me.invoke(listener, e)
+		if err != nil {
+			me.logger.Debugf(1, "Listener failed: type=%s, listener=%p, %v", e.Type(), listener, err)
+			errs = append(errs, &ListenerError{
+				Type:          e.Type(),
+				CurrentTarget: e.CurrentTarget(),
+				Listener:      listener,
+				Err:           err,
+			})
+			if policy == StopOnError {
+				return CanceledByEventHandler, errs
+			}
+		}
+		if e.ImmediatePropagationStopped() {
+			me.logger.Debugf(1, "Immediate propagation stopped: type=%s", e.Type())
+			return CanceledByEventHandler, errs
+		}
+	}
+	if e.PropagationStopped() {
+		me.logger.Debugf(1, "Propagation stopped: type=%s", e.Type())
+		return CanceledByEventHandler, errs
+	}
+	return NotCanceled, errs
+}
+
+// invoke calls the listener, and recovers from its panic according to the panic policy.
+func (me *EventTarget) invoke(listener *EventListener, e IEvent) (err error) {
+	policy := me.PanicPolicy()
+	if policy == SkipOnPanic || policy == DispatchOnPanic {
+		defer func() {
+			if x := recover(); x != nil {
+				target := e.CurrentTarget()
+				if e.EventPhase() == AtTarget {
+					target = e.Target()
+				}
+				perr := &PanicError{Value: x, Stack: debug.Stack()}
+				me.handlePanic(target, e, perr, policy)
+				err = perr
+			}
+		}()
+	}
+	return listener.Invoke(e)
+}
+
+// handlePanic logs the recovered panic, and dispatches it on the target with DispatchOnPanic.
+// A panic while another recovered one is being dispatched is only logged.
+func (me *EventTarget) handlePanic(target IEventTarget, e IEvent, err *PanicError, policy PanicPolicy) {
+	me.logger.Errorf("Recovered from panic: type=%s, %v\n%s", e.Type(), err.Value, err.Stack)
+
+	if policy != DispatchOnPanic {
+		return
+	}
+	if NewPanicEvent == nil {
+		me.logger.Warnf("Panic event not available, import the errorevent package: type=%s", e.Type())
+		return
+	}
+
+	// A panic while dispatching a panic event is not dispatched again, to avoid an endless chain.
+	pe := NewPanicEvent(target, err)
+	if !me.enterPanic(e, pe) {
+		return
+	}
+	defer me.leavePanic(pe)
+
+	me.DispatchEvent(pe)
+}
+
+// enterPanic records the panic event pe as in flight, unless e is one of the panic events in flight.
+func (me *EventTarget) enterPanic(e IEvent, pe IEvent) bool {
+	me.panicMtx.Lock()
+	defer me.panicMtx.Unlock()
+
+	for _, v := range me.panicEvents {
+		if v == e {
+			return false
+		}
+	}
+	me.panicEvents = append(me.panicEvents, pe)
+	return true
+}
+
+// leavePanic removes the panic event pe from the ones in flight.
+func (me *EventTarget) leavePanic(pe IEvent) {
+	me.panicMtx.Lock()
+	defer me.panicMtx.Unlock()
+
+	for i, v := range me.panicEvents {
+		if v == pe {
+			me.panicEvents = append(me.panicEvents[:i], me.panicEvents[i+1:]...)
+			return
+		}
+	}
+}
+
+// anonymousTarget is the target of the only dispatch in progress which didn't look up its goroutine ID.
+var anonymousTarget atomic.Pointer[EventTarget]
+
+// anonymousEntry and anonymousEnd are the code range of dispatchAnonymously, to be found on the stack.
+var anonymousEntry, anonymousEnd uintptr
+
+func init() {
+	anonymousEntry = reflect.ValueOf(dispatchAnonymously).Pointer()
+	for anonymousEnd = anonymousEntry + 1; ; anonymousEnd++ {
+		if f := runtime.FuncForPC(anonymousEnd); f == nil || f.Entry() != anonymousEntry {
+			break
+		}
+	}
+}
+
+// enter increases the recursion of the goroutine on this target, and returns the goroutine ID and the recursion.
+// The goroutine ID is expensive to look up, so a dispatch on an idle target doesn't look it up, as long as no other
+// target has such an anonymous dispatch in progress. Its goroutine ID is 0. A dispatch which arrives meanwhile looks
+// up its goroutine ID, and counts the anonymous dispatch only if it is nested in it. Since there is only one anonymous
+// dispatch at a time, dispatchAnonymously on the stack of a goroutine tells that it is the owner.
+func (me *EventTarget) enter() (int64, int32) {
+	me.depthMtx.Lock()
+	if !me.anonymous && len(me.depths) == 0 && anonymousTarget.CompareAndSwap(nil, me) {
+		me.anonymous = true
+		me.depthMtx.Unlock()
+		return 0, 1
+	}
+	me.depthMtx.Unlock()
+
+	goid := reentrant.GetCurrentGoroutineID()
+
+	me.depthMtx.Lock()
+	defer me.depthMtx.Unlock()
+
+	if me.depths == nil {
+		me.depths = make(map[int64]int32)
+	}
+	me.depths[goid]++
+	recursion := me.depths[goid]
+	if me.anonymous {
+		// Record the owner of the anonymous dispatch once it is found, which saves looking at the stack again.
+		if me.anonymousOwner == 0 && inAnonymousDispatch() {
+			me.anonymousOwner = goid
+		}
+		if me.anonymousOwner == goid {
+			recursion++
+		}
+	}
+	return goid, recursion
+}
+
+// leave decreases the recursion of the goroutine on this target.
+func (me *EventTarget) leave(goid int64) {
+	me.depthMtx.Lock()
+	defer me.depthMtx.Unlock()
+
+	if goid == 0 {
+		me.anonymous = false
+		me.anonymousOwner = 0
+		anonymousTarget.Store(nil)
+		return
+	}
+	if me.depths[goid]--; me.depths[goid] <= 0 {
+		delete(me.depths, goid)
+	}
+}
+
+// inAnonymousDispatch returns whether the calling goroutine is in the anonymous dispatch.
+func inAnonymousDispatch() bool {
+	var pcs [32]uintptr
+	for skip := 0; ; skip += len(pcs) {
+		n := runtime.Callers(skip, pcs[:])
+		for _, pc := range pcs[:n] {
+			// The return address follows the call.
+			if pc > anonymousEntry && pc <= anonymousEnd {
+				return true
+			}
+		}
+		if n < len(pcs) {
+			return false
+		}
+	}
+}
+
+// nextEntry returns the index of the match whose next entry is invoked first, which has the highest priority, and
+// was added first among equal priorities, or -1 if all of them are done. The entries of each match are in this order.
+func nextEntry(ms [][]*eventListenerEntry, pos []int) int {
+	next := -1
+	var best *eventListenerEntry
+	for i, entries := range ms {
+		if pos[i] == len(entries) {
+			continue
+		}
+		entry := entries[pos[i]]
+		if best == nil || entry.listener.options.Priority > best.listener.options.Priority ||
+			entry.listener.options.Priority == best.listener.options.Priority && entry.seq < best.seq {
+			next, best = i, entry
+		}
+	}
+	return next
+}
+
+// appendUnique appends the snapshots of src which are not in dst yet.
+func appendUnique(dst [][]*eventListenerEntry, src [][]*eventListenerEntry) [][]*eventListenerEntry {
+	n := len(dst)
+	for _, m := range src {
+		found := false
+		for _, v := range dst[:n] {
+			if &v[0] == &m[0] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, m)
+		}
+	}
+	return dst
+}
+
+// propagationPath returns the ancestors of this target, from the parent up to the root.
+func (me *EventTarget) propagationPath() []IEventTargetNode {
+	var (
+		path    []IEventTargetNode
+		visited map[IEventTargetNode]bool
+	)
+	for node := me.Parent(); node != nil; node = node.Parent() {
+		if visited == nil {
+			visited = make(map[IEventTargetNode]bool)
 		}
-		if e.PropagationStopped() {
-			me.logger.Debugf(1, "Propagation stopped: type=%s", e.Type())
-			return CanceledByEventHandler
+		if visited[node] {
+			panic(fmt.Sprintf("cyclic event target: %p", me))
 		}
+		visited[node] = true
+		path = append(path, node)
 	}
-	return NotCanceled
+	return path
 }
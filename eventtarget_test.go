@@ -0,0 +1,547 @@
+package events_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/oddengine/events"
+	"github.com/oddengine/events/errorevent"
+	Event "github.com/oddengine/events/event"
+)
+
+type node struct {
+	events.EventTarget
+	name string
+}
+
+func newNode(name string, parent *node) *node {
+	n := &node{name: name}
+	n.Init(nopLogger{})
+	if parent != nil {
+		n.SetParent(parent)
+	}
+	return n
+}
+
+func record(got *[]string, tag string) func(*Event.Event) {
+	return func(e *Event.Event) {
+		*got = append(*got, tag)
+	}
+}
+
+func equal(t *testing.T, got []string, want ...string) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestAddDuringDispatch(t *testing.T) {
+	var got []string
+	n := newNode("n", nil)
+	added := events.NewTypedEventListener(record(&got, "added"))
+	events.On(n, Event.CHANGE, func(e *Event.Event) {
+		got = append(got, "first")
+		n.AddEventListener(Event.CHANGE, added)
+	})
+
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	equal(t, got, "first")
+
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	equal(t, got, "first", "first", "added")
+}
+
+func TestRemoveDuringDispatch(t *testing.T) {
+	var got []string
+	n := newNode("n", nil)
+	var second *events.EventListener
+	events.On(n, Event.CHANGE, func(e *Event.Event) {
+		got = append(got, "first")
+		n.RemoveEventListener(Event.CHANGE, second)
+	})
+	second = events.On(n, Event.CHANGE, record(&got, "second"))
+
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	equal(t, got, "first", "first")
+}
+
+func TestConcurrentAddRemoveDuringDispatch(t *testing.T) {
+	var count int32
+	n := newNode("n", nil)
+	events.On(n, Event.CHANGE, func(e *Event.Event) {
+		atomic.AddInt32(&count, 1)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				l := events.On(n, Event.CHANGE, func(e *Event.Event) {})
+				n.DispatchEvent(Event.New(Event.CHANGE, n))
+				n.RemoveEventListener(Event.CHANGE, l)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if count != 800 {
+		t.Fatalf("count = %d, want 800", count)
+	}
+}
+
+func TestOnce(t *testing.T) {
+	var got []string
+	n := newNode("n", nil)
+	events.On(n, Event.CHANGE, record(&got, "once"), events.EventListenerOptions{Once: true})
+	events.On(n, Event.CHANGE, record(&got, "always"))
+
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	equal(t, got, "once", "always", "always")
+}
+
+func TestOnceSkipsOtherEventTypes(t *testing.T) {
+	count := 0
+	n := newNode("n", nil)
+	events.On(n, "*", func(e *errorevent.ErrorEvent) {
+		count++
+	}, events.EventListenerOptions{Once: true})
+	n.AddEventListener("*", events.NewEventListener(func(e *errorevent.ErrorEvent) {
+		count++
+	}, events.EventListenerOptions{Once: true}))
+
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	if count != 0 {
+		t.Fatalf("count = %d, want 0", count)
+	}
+	n.DispatchEvent(errorevent.New(errorevent.ERROR, n, "Error", errors.New("error")))
+	n.DispatchEvent(errorevent.New(errorevent.ERROR, n, "Error", errors.New("error")))
+	if count != 2 {
+		t.Fatalf("count = %d, want 2", count)
+	}
+}
+
+func TestOnceWithRecursion(t *testing.T) {
+	count := 0
+	n := newNode("n", nil)
+	events.On(n, Event.CHANGE, func(e *Event.Event) {
+		count++
+		n.DispatchEvent(Event.New(Event.CHANGE, n))
+	}, events.EventListenerOptions{Once: true})
+
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	if count != 1 {
+		t.Fatalf("count = %d, want 1", count)
+	}
+}
+
+func TestMaxRecursion(t *testing.T) {
+	count := int32(0)
+	n := newNode("n", nil)
+	events.On(n, Event.CHANGE, func(e *Event.Event) {
+		count++
+		n.DispatchEvent(Event.New(Event.CHANGE, n))
+	})
+
+	_, err := n.DispatchEventWithError(Event.New(Event.CHANGE, n))
+	if count != events.MAX_RECURSION {
+		t.Fatalf("count = %d, want %d", count, events.MAX_RECURSION)
+	}
+	if err != nil {
+		t.Fatalf("unexpected error of the outer dispatch: %v", err)
+	}
+}
+
+func TestMaxRecursionWithConcurrentDispatch(t *testing.T) {
+	count := int32(0)
+	n := newNode("n", nil)
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	events.On(n, Event.OPEN, func(e *Event.Event) {
+		close(entered)
+		<-release
+		n.DispatchEvent(Event.New(Event.CHANGE, n))
+	})
+	events.On(n, Event.CHANGE, func(e *Event.Event) {
+		count++
+		n.DispatchEvent(Event.New(Event.CHANGE, n))
+	})
+
+	done := make(chan struct{})
+	go func() {
+		n.DispatchEvent(Event.New(Event.OPEN, n))
+		close(done)
+	}()
+	<-entered
+
+	// The dispatch in progress on another goroutine is not counted.
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	if count != events.MAX_RECURSION {
+		t.Fatalf("count = %d, want %d", count, events.MAX_RECURSION)
+	}
+
+	// The recursion nested in the dispatch in progress counts it.
+	count = 0
+	close(release)
+	<-done
+	if count != events.MAX_RECURSION-1 {
+		t.Fatalf("nested count = %d, want %d", count, events.MAX_RECURSION-1)
+	}
+
+	count = 0
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	if count != events.MAX_RECURSION {
+		t.Fatalf("count = %d, want %d", count, events.MAX_RECURSION)
+	}
+}
+
+func TestMaxRecursionWithDispatchOnOtherTarget(t *testing.T) {
+	count := int32(0)
+	n := newNode("n", nil)
+	other := newNode("other", nil)
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	events.On(other, Event.OPEN, func(e *Event.Event) {
+		close(entered)
+		<-release
+		n.DispatchEvent(Event.New(Event.CHANGE, n))
+	})
+	events.On(n, Event.CHANGE, func(e *Event.Event) {
+		count++
+		n.DispatchEvent(Event.New(Event.CHANGE, n))
+	})
+
+	done := make(chan struct{})
+	go func() {
+		other.DispatchEvent(Event.New(Event.OPEN, other))
+		close(done)
+	}()
+	<-entered
+
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	if count != events.MAX_RECURSION {
+		t.Fatalf("count = %d, want %d", count, events.MAX_RECURSION)
+	}
+
+	// The dispatch in progress on the other target is not counted on this one.
+	count = 0
+	close(release)
+	<-done
+	if count != events.MAX_RECURSION {
+		t.Fatalf("nested count = %d, want %d", count, events.MAX_RECURSION)
+	}
+}
+
+func TestPriority(t *testing.T) {
+	var got []string
+	n := newNode("n", nil)
+	events.On(n, Event.CHANGE, record(&got, "0a"))
+	events.On(n, Event.CHANGE, record(&got, "-1"), events.EventListenerOptions{Priority: -1})
+	events.On(n, Event.CHANGE, record(&got, "1"), events.EventListenerOptions{Priority: 1})
+	events.On(n, Event.CHANGE, record(&got, "0b"))
+
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	equal(t, got, "1", "0a", "0b", "-1")
+}
+
+func TestEventFlow(t *testing.T) {
+	var got []string
+	root := newNode("root", nil)
+	parent := newNode("parent", root)
+	child := newNode("child", parent)
+	for _, n := range []*node{root, parent, child} {
+		n := n
+		events.On(n, Event.CHANGE, func(e *Event.Event) {
+			got = append(got, "capture:"+n.name)
+		}, events.EventListenerOptions{Capture: true})
+		events.On(n, Event.CHANGE, func(e *Event.Event) {
+			got = append(got, "bubble:"+n.name)
+			if e.EventPhase() == events.AtTarget {
+				got[len(got)-1] = "target:" + n.name
+			}
+		})
+	}
+
+	child.DispatchEvent(Event.New(Event.CHANGE, child))
+	equal(t, got, "capture:root", "capture:parent", "target:child")
+
+	got = nil
+	child.DispatchEvent(Event.New(Event.CHANGE, child, Event.EventOptions{Bubbles: true}))
+	equal(t, got, "capture:root", "capture:parent", "target:child", "bubble:parent", "bubble:root")
+}
+
+type Conn struct {
+	events.EventTarget
+
+	ID string
+}
+
+func TestCurrentTarget(t *testing.T) {
+	var got []string
+	server := &Conn{ID: "server"}
+	server.Init(nopLogger{})
+	conn := &Conn{ID: "conn"}
+	conn.Init(nopLogger{})
+	conn.SetParent(server)
+
+	current := func(e events.IEvent) {
+		got = append(got, e.CurrentTarget().(*Conn).ID)
+	}
+	events.On(server, Event.CHANGE, func(e *Event.Event) {
+		current(e)
+	}, events.EventListenerOptions{Capture: true})
+	events.On(server, Event.CHANGE, func(e *Event.Event) {
+		current(e)
+	})
+	events.On(conn, Event.CHANGE, func(e *Event.Event) {
+		current(e)
+	})
+	conn.SetDefaultAction(Event.CHANGE, current)
+
+	conn.DispatchEvent(Event.New(Event.CHANGE, conn, Event.EventOptions{Bubbles: true}))
+	equal(t, got, "server", "conn", "server", "conn")
+
+	// An event of another target dispatched here has no outer object to resolve.
+	got = nil
+	events.On(conn, Event.CLOSE, func(e *Event.Event) {
+		if e.CurrentTarget() != &conn.EventTarget {
+			t.Fatalf("CurrentTarget() = %v, want the EventTarget", e.CurrentTarget())
+		}
+	})
+	conn.DispatchEvent(Event.New(Event.CLOSE, server))
+}
+
+func TestStopPropagation(t *testing.T) {
+	var got []string
+	parent := newNode("parent", nil)
+	child := newNode("child", parent)
+	events.On(child, Event.CHANGE, func(e *Event.Event) {
+		got = append(got, "first")
+		e.StopPropagation()
+	})
+	events.On(child, Event.CHANGE, record(&got, "second"))
+	events.On(parent, Event.CHANGE, record(&got, "parent"))
+
+	res := child.DispatchEvent(Event.New(Event.CHANGE, child, Event.EventOptions{Bubbles: true}))
+	equal(t, got, "first", "second")
+	if res != events.CanceledByEventHandler {
+		t.Fatalf("res = %v, want CanceledByEventHandler", res)
+	}
+
+	got = nil
+	events.On(child, Event.CLOSE, func(e *Event.Event) {
+		got = append(got, "first")
+		e.StopImmediatePropagation()
+	})
+	events.On(child, Event.CLOSE, record(&got, "second"))
+
+	child.DispatchEvent(Event.New(Event.CLOSE, child))
+	equal(t, got, "first")
+}
+
+func TestDefaultAction(t *testing.T) {
+	executed := false
+	n := newNode("n", nil)
+	n.SetDefaultAction(Event.CLOSE, func(e events.IEvent) {
+		executed = true
+	})
+
+	if res := n.DispatchEvent(Event.New(Event.CLOSE, n, Event.EventOptions{Cancelable: true})); res != events.CanceledByDefaultEventHandler || !executed {
+		t.Fatalf("res = %v, executed = %v", res, executed)
+	}
+
+	executed = false
+	events.On(n, Event.CLOSE, func(e *Event.Event) {
+		e.PreventDefault()
+	})
+	if res := n.DispatchEvent(Event.New(Event.CLOSE, n, Event.EventOptions{Cancelable: true})); res != events.CanceledByEventHandler || executed {
+		t.Fatalf("res = %v, executed = %v", res, executed)
+	}
+	if res := n.DispatchEvent(Event.New(Event.CLOSE, n)); res != events.CanceledByDefaultEventHandler || !executed {
+		t.Fatalf("not cancelable: res = %v, executed = %v", res, executed)
+	}
+}
+
+func TestDispatchEventWithError(t *testing.T) {
+	errFirst := errors.New("first")
+	errSecond := errors.New("second")
+	n := newNode("n", nil)
+	events.OnWithError(n, Event.CHANGE, func(e *Event.Event) error {
+		return errFirst
+	})
+	events.OnWithError(n, Event.CHANGE, func(e *Event.Event) error {
+		return errSecond
+	})
+
+	_, err := n.DispatchEventWithError(Event.New(Event.CHANGE, n))
+	var de *events.DispatchError
+	if !errors.As(err, &de) || len(de.Errors) != 2 || !errors.Is(err, errFirst) || !errors.Is(err, errSecond) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	n.SetErrorPolicy(events.StopOnError)
+	_, err = n.DispatchEventWithError(Event.New(Event.CHANGE, n))
+	if !errors.Is(err, errFirst) || errors.Is(err, errSecond) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSkipOnPanic(t *testing.T) {
+	var got []string
+	n := newNode("n", nil)
+	n.SetPanicPolicy(events.SkipOnPanic)
+	events.On(n, Event.CHANGE, func(e *Event.Event) {
+		panic("boom")
+	})
+	events.On(n, Event.CHANGE, record(&got, "second"))
+
+	_, err := n.DispatchEventWithError(Event.New(Event.CHANGE, n))
+	var perr *events.PanicError
+	if !errors.As(err, &perr) || perr.Value != "boom" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	equal(t, got, "second")
+}
+
+func TestSkipOnPanicLoggedOnce(t *testing.T) {
+	logger := new(errorLogger)
+	n := &node{name: "n"}
+	n.Init(logger)
+	n.SetPanicPolicy(events.SkipOnPanic)
+	events.On(n, Event.CHANGE, func(e *Event.Event) {
+		panic("boom")
+	})
+	events.OnWithError(n, Event.CHANGE, func(e *Event.Event) error {
+		return errors.New("failed")
+	})
+
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	if len(logger.errs) != 2 ||
+		!strings.Contains(logger.errs[0], "Recovered from panic: type=change, boom") ||
+		!strings.Contains(logger.errs[1], "Failed to handle event") ||
+		strings.Contains(logger.errs[1], "boom") || !strings.Contains(logger.errs[1], "failed") {
+		t.Fatalf("unexpected errors: %q", logger.errs)
+	}
+}
+
+func TestDispatchOnPanic(t *testing.T) {
+	var got []*errorevent.ErrorEvent
+	parent := newNode("parent", nil)
+	child := newNode("child", parent)
+	child.SetPanicPolicy(events.DispatchOnPanic)
+	events.On(child, Event.CHANGE, func(e *Event.Event) {
+		panic("boom")
+	})
+	events.On(child, errorevent.ERROR, func(e *errorevent.ErrorEvent) {
+		got = append(got, e)
+		// A panic while dispatching the panic event is not dispatched again.
+		panic("boom again")
+	})
+
+	_, err := child.DispatchEventWithError(Event.New(Event.CHANGE, child))
+	var perr *events.PanicError
+	if !errors.As(err, &perr) || perr.Value != "boom" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].Name != errorevent.PANIC || got[0].Target() != child || !errors.Is(got[0].Message, perr) {
+		t.Fatalf("unexpected panic events: %v", got)
+	}
+}
+
+func TestDispatchOnPanicConcurrently(t *testing.T) {
+	var count int32
+	n := newNode("n", nil)
+	n.SetPanicPolicy(events.DispatchOnPanic)
+	events.On(n, Event.CHANGE, func(e *Event.Event) {
+		panic("boom")
+	})
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	events.On(n, errorevent.ERROR, func(e *errorevent.ErrorEvent) {
+		if atomic.AddInt32(&count, 1) == 1 {
+			close(entered)
+			<-release
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		n.DispatchEvent(Event.New(Event.CHANGE, n))
+		close(done)
+	}()
+	<-entered
+
+	// A panic on another goroutine is dispatched, while the first panic event is in flight.
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	close(release)
+	<-done
+	if count != 2 {
+		t.Fatalf("count = %d, want 2", count)
+	}
+}
+
+func TestRepanicOnPanic(t *testing.T) {
+	n := newNode("n", nil)
+	n.SetPanicPolicy(events.RepanicOnPanic)
+	events.On(n, Event.CHANGE, func(e *Event.Event) {
+		panic("boom")
+	})
+
+	defer func() {
+		if x := recover(); x != "boom" {
+			t.Fatalf("unexpected panic: %v", x)
+		}
+	}()
+	n.DispatchEventWithError(Event.New(Event.CHANGE, n))
+	t.Fatalf("panic not propagated")
+}
+
+func TestPatterns(t *testing.T) {
+	var got []string
+	n := newNode("n", nil)
+	events.On(n, "*", record(&got, "*"))
+	events.On(n, "NetStream.*", record(&got, "NetStream.*"))
+	events.On(n, "NetStream.Play.*", record(&got, "NetStream.Play.*"))
+	events.On(n, "NetStream.Play.Start", record(&got, "NetStream.Play.Start"))
+
+	// Equal priorities are invoked in the order they were added, regardless of the pattern.
+	n.DispatchEvent(Event.New("NetStream.Play.Start", n))
+	equal(t, got, "*", "NetStream.*", "NetStream.Play.*", "NetStream.Play.Start")
+
+	got = nil
+	n.DispatchEvent(Event.New("NetStream.Seek.Notify", n))
+	equal(t, got, "*", "NetStream.*")
+}
+
+func TestPatternsWithPriority(t *testing.T) {
+	var got []string
+	n := newNode("n", nil)
+	events.On(n, "NetStream.Play.Start", record(&got, "business"))
+	events.On(n, "NetStream.*", record(&got, "log"), events.EventListenerOptions{Priority: 50})
+	events.On(n, "NetStream.Play.*", record(&got, "metrics"))
+	events.On(n, "*", record(&got, "auth"), events.EventListenerOptions{Priority: 100})
+	events.On(n, "NetStream.Play.Start", record(&got, "validate"), events.EventListenerOptions{Priority: 50})
+	events.On(n, "*", record(&got, "fallback"), events.EventListenerOptions{Priority: -1})
+
+	n.DispatchEvent(Event.New("NetStream.Play.Start", n))
+	equal(t, got, "auth", "log", "validate", "business", "metrics", "fallback")
+
+	got = nil
+	n.DispatchEvent(Event.New("NetStream.Seek.Notify", n))
+	equal(t, got, "auth", "log", "fallback")
+}
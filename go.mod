@@ -1,5 +1,5 @@
 module github.com/oddengine/events
 
-go 1.16
+go 1.20
 
 require github.com/oddengine/log v0.0.0-20230313074506-0902dd3886fa
@@ -0,0 +1,182 @@
+package events
+
+import (
+	"errors"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+
+	"github.com/oddengine/events/reentrant"
+	"github.com/oddengine/log"
+)
+
+var (
+	// ErrLoopClosed is returned by the dispatches posted to a closed Loop.
+	ErrLoopClosed = errors.New("loop closed")
+)
+
+// Loop runs tasks one by one on its own goroutine, in the order they were posted.
+// After each task, the microtask queue is drained, including the microtasks queued by microtasks.
+// All of the dispatches of the targets bound to a Loop run on it, so they never run concurrently, and happen in a
+// deterministic order. A panicking task or microtask is recovered, and the loop keeps running.
+type Loop struct {
+	logger       log.ILogger
+	mtx          sync.Mutex
+	cond         sync.Cond
+	tasks        []func()
+	microtasks   []func()
+	closed       bool
+	goid         int64
+	running      atomic.Bool
+	done         chan struct{}
+	panicHandler func(err *PanicError)
+}
+
+// Init this class, and starts the goroutine.
+func (me *Loop) Init(logger log.ILogger) *Loop {
+	me.logger = logger
+	me.cond.L = &me.mtx
+	me.done = make(chan struct{})
+	go me.run()
+	return me
+}
+
+// SetPanicHandler sets the function called on the loop with the panic of a task or microtask.
+// If it is nil, the panic is logged.
+func (me *Loop) SetPanicHandler(handler func(err *PanicError)) {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	me.panicHandler = handler
+}
+
+// Post queues the task, and returns false if the loop is closed.
+func (me *Loop) Post(task func()) bool {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	if me.closed {
+		return false
+	}
+	me.tasks = append(me.tasks, task)
+	me.cond.Signal()
+	return true
+}
+
+// Enqueue queues a task which dispatches the event on the target, and returns false if the loop is closed.
+func (me *Loop) Enqueue(target IEventTarget, e IEvent) bool {
+	return me.Post(func() {
+		target.DispatchEvent(e)
+	})
+}
+
+// QueueMicrotask queues the microtask, which is executed after the current task, before any other task.
+// It returns false if the loop is closed.
+func (me *Loop) QueueMicrotask(microtask func()) bool {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	if me.closed && !me.InLoop() {
+		return false
+	}
+	me.microtasks = append(me.microtasks, microtask)
+	me.cond.Signal()
+	return true
+}
+
+// InLoop returns whether the calling goroutine is the one of this loop.
+func (me *Loop) InLoop() bool {
+	// Only a task runs on the loop, which saves looking up the goroutine ID while it is idle.
+	return me.running.Load() && atomic.LoadInt64(&me.goid) == reentrant.GetCurrentGoroutineID()
+}
+
+// Close stops the loop after the queued tasks are done, and waits for it.
+// Calling Close from a task of this loop doesn't wait.
+func (me *Loop) Close() {
+	me.mtx.Lock()
+	me.closed = true
+	me.cond.Broadcast()
+	me.mtx.Unlock()
+
+	if !me.InLoop() {
+		<-me.done
+	}
+}
+
+// Done returns a channel which is closed when the loop is stopped.
+func (me *Loop) Done() <-chan struct{} {
+	return me.done
+}
+
+func (me *Loop) run() {
+	atomic.StoreInt64(&me.goid, reentrant.GetCurrentGoroutineID())
+	defer close(me.done)
+
+	for {
+		me.mtx.Lock()
+		for len(me.tasks) == 0 && len(me.microtasks) == 0 && !me.closed {
+			me.cond.Wait()
+		}
+		if len(me.tasks) == 0 && len(me.microtasks) == 0 {
+			me.mtx.Unlock()
+			return
+		}
+		var task func()
+		if len(me.tasks) > 0 {
+			task = me.tasks[0]
+			me.tasks[0] = nil
+			me.tasks = me.tasks[1:]
+		}
+		me.mtx.Unlock()
+
+		if task != nil {
+			me.execute(task)
+		}
+		me.drainMicrotasks()
+	}
+}
+
+func (me *Loop) drainMicrotasks() {
+	for {
+		me.mtx.Lock()
+		if len(me.microtasks) == 0 {
+			me.mtx.Unlock()
+			return
+		}
+		microtask := me.microtasks[0]
+		me.microtasks[0] = nil
+		me.microtasks = me.microtasks[1:]
+		me.mtx.Unlock()
+
+		me.execute(microtask)
+	}
+}
+
+// execute runs the task, and recovers from its panic.
+func (me *Loop) execute(task func()) {
+	me.running.Store(true)
+	defer me.running.Store(false)
+
+	defer func() {
+		if x := recover(); x != nil {
+			err := &PanicError{Value: x, Stack: debug.Stack()}
+
+			me.mtx.Lock()
+			handler := me.panicHandler
+			me.mtx.Unlock()
+
+			if handler == nil {
+				me.logger.Errorf("Recovered from panic in loop: %v\n%s", x, err.Stack)
+				return
+			}
+			handler(err)
+		}
+	}()
+
+	task()
+}
+
+// NewLoop creates a new Loop, and starts its goroutine.
+func NewLoop(logger log.ILogger) *Loop {
+	return new(Loop).Init(logger)
+}
@@ -0,0 +1,206 @@
+package events_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/oddengine/events"
+	Event "github.com/oddengine/events/event"
+)
+
+func TestLoopOrder(t *testing.T) {
+	var got []string
+	loop := events.NewLoop(nopLogger{})
+	done := make(chan struct{})
+
+	loop.Post(func() {
+		got = append(got, "task 1")
+		loop.QueueMicrotask(func() {
+			got = append(got, "microtask 1")
+			// Queued by a microtask, still before the next task.
+			loop.QueueMicrotask(func() {
+				got = append(got, "microtask 2")
+			})
+		})
+		loop.Post(func() {
+			got = append(got, "task 3")
+			close(done)
+		})
+	})
+	loop.Post(func() {
+		got = append(got, "task 2")
+	})
+	<-done
+	loop.Close()
+
+	equal(t, got, "task 1", "microtask 1", "microtask 2", "task 2", "task 3")
+}
+
+func TestLoopEnqueue(t *testing.T) {
+	var got []string
+	loop := events.NewLoop(nopLogger{})
+	n := newNode("n", nil)
+	n.SetLoop(loop)
+	events.On(n, "*", func(e *Event.Event) {
+		if !loop.InLoop() {
+			t.Errorf("dispatched out of the loop")
+		}
+		got = append(got, e.Type())
+	})
+
+	for i := 0; i < 10; i++ {
+		if i%2 == 0 {
+			loop.Enqueue(n, Event.New(fmt.Sprint(i), n))
+		} else {
+			n.DispatchEventAsync(Event.New(fmt.Sprint(i), n))
+		}
+	}
+	loop.Close()
+
+	equal(t, got, "0", "1", "2", "3", "4", "5", "6", "7", "8", "9")
+	if loop.InLoop() {
+		t.Fatalf("InLoop on another goroutine")
+	}
+}
+
+func TestLoopCloseInLoop(t *testing.T) {
+	var got []string
+	loop := events.NewLoop(nopLogger{})
+
+	loop.Post(func() {
+		// Doesn't wait for itself, and the queued tasks are still done.
+		loop.Close()
+		got = append(got, "task 1")
+		if loop.Post(func() {}) {
+			t.Errorf("task posted to a closed loop")
+		}
+		loop.QueueMicrotask(func() {
+			got = append(got, "microtask")
+		})
+	})
+	loop.Post(func() {
+		got = append(got, "task 2")
+	})
+	<-loop.Done()
+
+	equal(t, got, "task 1", "microtask", "task 2")
+	if loop.Post(func() {}) || loop.QueueMicrotask(func() {}) {
+		t.Fatalf("task posted to a closed loop")
+	}
+
+	n := newNode("n", nil)
+	n.SetLoop(loop)
+	if _, err := n.DispatchEventAsync(Event.New(Event.CHANGE, n)).Wait(); err != events.ErrLoopClosed {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLoopPanic(t *testing.T) {
+	var (
+		got  []string
+		errs []*events.PanicError
+	)
+	loop := events.NewLoop(nopLogger{})
+	loop.SetPanicHandler(func(err *events.PanicError) {
+		errs = append(errs, err)
+	})
+
+	loop.Post(func() {
+		loop.QueueMicrotask(func() {
+			panic("microtask")
+		})
+		loop.QueueMicrotask(func() {
+			got = append(got, "microtask")
+		})
+		panic("task")
+	})
+	loop.Post(func() {
+		got = append(got, "task")
+	})
+	loop.Close()
+
+	equal(t, got, "microtask", "task")
+	if len(errs) != 2 || errs[0].Value != "task" || errs[1].Value != "microtask" {
+		t.Fatalf("unexpected panics: %v", errs)
+	}
+}
+
+// errorLogger records the errors logged.
+type errorLogger struct {
+	nopLogger
+	mtx  sync.Mutex
+	errs []string
+}
+
+func (me *errorLogger) Errorf(format string, args ...interface{}) {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	me.errs = append(me.errs, fmt.Sprintf(format, args...))
+}
+
+func TestLoopPanicLogged(t *testing.T) {
+	logger := new(errorLogger)
+	loop := events.NewLoop(logger)
+	loop.Post(func() {
+		panic("task")
+	})
+	loop.Close()
+
+	if len(logger.errs) != 1 || !strings.Contains(logger.errs[0], "Recovered from panic in loop: task") {
+		t.Fatalf("unexpected errors: %v", logger.errs)
+	}
+}
+
+func TestLoopDispatch(t *testing.T) {
+	var got []string
+	loop := events.NewLoop(nopLogger{})
+	n := newNode("n", nil)
+	n.SetLoop(loop)
+	events.On(n, Event.CHANGE, func(e *Event.Event) {
+		got = append(got, fmt.Sprintf("dispatch:%v", loop.InLoop()))
+	})
+
+	loop.Post(func() {
+		got = append(got, "task")
+	})
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	equal(t, got, "task", "dispatch:true")
+
+	loop.Close()
+	if _, err := n.DispatchEventWithError(Event.New(Event.CHANGE, n)); !errors.Is(err, events.ErrLoopClosed) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLoopDispatchRepanic(t *testing.T) {
+	loop := events.NewLoop(nopLogger{})
+	defer loop.Close()
+
+	n := newNode("n", nil)
+	n.SetLoop(loop)
+	n.SetPanicPolicy(events.RepanicOnPanic)
+	events.On(n, Event.CHANGE, func(e *Event.Event) {
+		panic("boom")
+	})
+
+	func() {
+		defer func() {
+			if x := recover(); x != "boom" {
+				t.Fatalf("unexpected panic: %v", x)
+			}
+		}()
+		n.DispatchEvent(Event.New(Event.CHANGE, n))
+		t.Fatalf("panic not propagated")
+	}()
+
+	// The loop keeps running.
+	done := make(chan struct{})
+	if !loop.Post(func() { close(done) }) {
+		t.Fatalf("loop closed")
+	}
+	<-done
+}
@@ -1,72 +1,75 @@
 package code
 
+// Code identifies a NetStatusEvent, in the form of Category.Subject[.Detail].
+type Code string
+
 // NetStatusEvent codes.
 const (
-	NETCONNECTION_CALL_FAILED            = "NetConnection.Call.Failed"
-	NETCONNECTION_CALL_SUCCESS           = "NetConnection.Call.Success"
-	NETCONNECTION_CONNECT_APPSHUTDOWN    = "NetConnection.Connect.AppShutdown"
-	NETCONNECTION_CONNECT_BADGATEWAY     = "NetConnection.Connect.BadGateway"
-	NETCONNECTION_CONNECT_CLOSED         = "NetConnection.Connect.Closed"
-	NETCONNECTION_CONNECT_FAILED         = "NetConnection.Connect.Failed"
-	NETCONNECTION_CONNECT_GATEWAYTIMEOUT = "NetConnection.Connect.GatewayTimeout"
-	NETCONNECTION_CONNECT_IDLETIMEOUT    = "NetConnection.Connect.IdleTimeout"
-	NETCONNECTION_CONNECT_INVALIDAPP     = "NetConnection.Connect.InvalidApp"
-	NETCONNECTION_CONNECT_NETWORKCHANGE  = "NetConnection.Connect.NetworkChange"
-	NETCONNECTION_CONNECT_REJECTED       = "NetConnection.Connect.Rejected"
-	NETCONNECTION_CONNECT_SUCCESS        = "NetConnection.Connect.Success"
+	NETCONNECTION_CALL_FAILED            Code = "NetConnection.Call.Failed"
+	NETCONNECTION_CALL_SUCCESS           Code = "NetConnection.Call.Success"
+	NETCONNECTION_CONNECT_APPSHUTDOWN    Code = "NetConnection.Connect.AppShutdown"
+	NETCONNECTION_CONNECT_BADGATEWAY     Code = "NetConnection.Connect.BadGateway"
+	NETCONNECTION_CONNECT_CLOSED         Code = "NetConnection.Connect.Closed"
+	NETCONNECTION_CONNECT_FAILED         Code = "NetConnection.Connect.Failed"
+	NETCONNECTION_CONNECT_GATEWAYTIMEOUT Code = "NetConnection.Connect.GatewayTimeout"
+	NETCONNECTION_CONNECT_IDLETIMEOUT    Code = "NetConnection.Connect.IdleTimeout"
+	NETCONNECTION_CONNECT_INVALIDAPP     Code = "NetConnection.Connect.InvalidApp"
+	NETCONNECTION_CONNECT_NETWORKCHANGE  Code = "NetConnection.Connect.NetworkChange"
+	NETCONNECTION_CONNECT_REJECTED       Code = "NetConnection.Connect.Rejected"
+	NETCONNECTION_CONNECT_SUCCESS        Code = "NetConnection.Connect.Success"
 
-	NETGROUP_CONNECT_CLOSED                  = "NetGroup.Connect.Closed"
-	NETGROUP_CONNECT_FAILED                  = "NetGroup.Connect.Failed"
-	NETGROUP_CONNECT_REJECTED                = "NetGroup.Connect.Rejected"
-	NETGROUP_CONNECT_SUCCESS                 = "NetGroup.Connect.Success"
-	NETGROUP_LOCALCOVERAGE_NOTIFY            = "NetGroup.LocalCoverage.Notify"
-	NETGROUP_MULTICASTSTREAM_PUBLISHNOTIFY   = "NetGroup.MulticastStream.PublishNotify"
-	NETGROUP_MULTICASTSTREAM_UNPUBLISHNOTIFY = "NetGroup.MulticastStream.UnpublishNotify"
-	NETGROUP_NEIGHBOR_CONNECT                = "NetGroup.Neighbor.Connect"
-	NETGROUP_NEIGHBOR_DISCONNECT             = "NetGroup.Neighbor.Disconnect"
-	NETGROUP_POSTING_FAILED                  = "NetGroup.Posting.Failed"
-	NETGROUP_POSTING_NOTIFY                  = "NetGroup.Posting.Notify"
-	NETGROUP_REPLICATION_FETCH_FAILED        = "NetGroup.Replication.Fetch.Failed"
-	NETGROUP_REPLICATION_FETCH_RESULT        = "NetGroup.Replication.Fetch.Result"
-	NETGROUP_REPLICATION_FETCH_SENDNOTIFY    = "NetGroup.Replication.Fetch.SendNotify"
-	NETGROUP_REPLICATION_REQUEST             = "NetGroup.Replication.Request"
-	NETGROUP_SENDTO_FAILED                   = "NetGroup.SendTo.Failed"
-	NETGROUP_SENDTO_NOTIFY                   = "NetGroup.SendTo.Notify"
+	NETGROUP_CONNECT_CLOSED                  Code = "NetGroup.Connect.Closed"
+	NETGROUP_CONNECT_FAILED                  Code = "NetGroup.Connect.Failed"
+	NETGROUP_CONNECT_REJECTED                Code = "NetGroup.Connect.Rejected"
+	NETGROUP_CONNECT_SUCCESS                 Code = "NetGroup.Connect.Success"
+	NETGROUP_LOCALCOVERAGE_NOTIFY            Code = "NetGroup.LocalCoverage.Notify"
+	NETGROUP_MULTICASTSTREAM_PUBLISHNOTIFY   Code = "NetGroup.MulticastStream.PublishNotify"
+	NETGROUP_MULTICASTSTREAM_UNPUBLISHNOTIFY Code = "NetGroup.MulticastStream.UnpublishNotify"
+	NETGROUP_NEIGHBOR_CONNECT                Code = "NetGroup.Neighbor.Connect"
+	NETGROUP_NEIGHBOR_DISCONNECT             Code = "NetGroup.Neighbor.Disconnect"
+	NETGROUP_POSTING_FAILED                  Code = "NetGroup.Posting.Failed"
+	NETGROUP_POSTING_NOTIFY                  Code = "NetGroup.Posting.Notify"
+	NETGROUP_REPLICATION_FETCH_FAILED        Code = "NetGroup.Replication.Fetch.Failed"
+	NETGROUP_REPLICATION_FETCH_RESULT        Code = "NetGroup.Replication.Fetch.Result"
+	NETGROUP_REPLICATION_FETCH_SENDNOTIFY    Code = "NetGroup.Replication.Fetch.SendNotify"
+	NETGROUP_REPLICATION_REQUEST             Code = "NetGroup.Replication.Request"
+	NETGROUP_SENDTO_FAILED                   Code = "NetGroup.SendTo.Failed"
+	NETGROUP_SENDTO_NOTIFY                   Code = "NetGroup.SendTo.Notify"
 
-	NETSTREAM_BUFFER_EMPTY               = "NetStream.Buffer.Empty"
-	NETSTREAM_BUFFER_FLUSH               = "NetStream.Buffer.Flush"
-	NETSTREAM_BUFFER_FULL                = "NetStream.Buffer.Full"
-	NETSTREAM_FAILED                     = "NetStream.Failed"
-	NETSTREAM_INVOKE_FAILED              = "NetStream.Invoke.Failed"
-	NETSTREAM_INVOKE_START               = "NetStream.Invoke.Start"
-	NETSTREAM_INVOKE_STOP                = "NetStream.Invoke.Stop"
-	NETSTREAM_INVOKE_TIMEOUT             = "NetStream.Invoke.Timeout"
-	NETSTREAM_PAUSE_NOTIFY               = "NetStream.Pause.Notify"
-	NETSTREAM_PLAY_FAILED                = "NetStream.Play.Failed"
-	NETSTREAM_PLAY_FILESTRUCTUREINVALID  = "NetStream.Play.FileStructureInvalid"
-	NETSTREAM_PLAY_INSUFFICIENTBW        = "NetStream.Play.InsufficientBW"
-	NETSTREAM_PLAY_NOSUPPORTEDTRACKFOUND = "NetStream.Play.NoSupportedTrackFound"
-	NETSTREAM_PLAY_PUBLISHNOTIFY         = "NetStream.Play.PublishNotify"
-	NETSTREAM_PLAY_RESET                 = "NetStream.Play.Reset"
-	NETSTREAM_PLAY_START                 = "NetStream.Play.Start"
-	NETSTREAM_PLAY_STOP                  = "NetStream.Play.Stop"
-	NETSTREAM_PLAY_STREAMNOTFOUND        = "NetStream.Play.StreamNotFound"
-	NETSTREAM_PLAY_TRANSITION            = "NetStream.Play.Transition"
-	NETSTREAM_PLAY_UNPUBLISHNOTIFY       = "NetStream.Play.UnpublishNotify"
-	NETSTREAM_PUBLISH_BADNAME            = "NetStream.Publish.BadName"
-	NETSTREAM_PUBLISH_REJECTED           = "NetStream.Publish.Rejected"
-	NETSTREAM_PUBLISH_IDLE               = "NetStream.Publish.Idle"
-	NETSTREAM_PUBLISH_START              = "NetStream.Publish.Start"
-	NETSTREAM_RECORD_ALREADYEXISTS       = "NetStream.Record.AlreadyExists"
-	NETSTREAM_RECORD_FAILED              = "NetStream.Record.Failed"
-	NETSTREAM_RECORD_NOACCESS            = "NetStream.Record.NoAccess"
-	NETSTREAM_RECORD_START               = "NetStream.Record.Start"
-	NETSTREAM_RECORD_STOP                = "NetStream.Record.Stop"
-	NETSTREAM_SEEK_FAILED                = "NetStream.Seek.Failed"
-	NETSTREAM_SEEK_INVALIDTIME           = "NetStream.Seek.InvalidTime"
-	NETSTREAM_SEEK_NOTIFY                = "NetStream.Seek.Notify"
-	NETSTREAM_STEP_NOTIFY                = "NetStream.Step.Notify"
-	NETSTREAM_UNPAUSE_NOTIFY             = "NetStream.Unpause.Notify"
-	NETSTREAM_UNPUBLISH_SUCCESS          = "NetStream.Unpublish.Success"
-	NETSTREAM_VIDEO_DIMENSIONCHANGE      = "NetStream.Video.DimensionChange"
+	NETSTREAM_BUFFER_EMPTY               Code = "NetStream.Buffer.Empty"
+	NETSTREAM_BUFFER_FLUSH               Code = "NetStream.Buffer.Flush"
+	NETSTREAM_BUFFER_FULL                Code = "NetStream.Buffer.Full"
+	NETSTREAM_FAILED                     Code = "NetStream.Failed"
+	NETSTREAM_INVOKE_FAILED              Code = "NetStream.Invoke.Failed"
+	NETSTREAM_INVOKE_START               Code = "NetStream.Invoke.Start"
+	NETSTREAM_INVOKE_STOP                Code = "NetStream.Invoke.Stop"
+	NETSTREAM_INVOKE_TIMEOUT             Code = "NetStream.Invoke.Timeout"
+	NETSTREAM_PAUSE_NOTIFY               Code = "NetStream.Pause.Notify"
+	NETSTREAM_PLAY_FAILED                Code = "NetStream.Play.Failed"
+	NETSTREAM_PLAY_FILESTRUCTUREINVALID  Code = "NetStream.Play.FileStructureInvalid"
+	NETSTREAM_PLAY_INSUFFICIENTBW        Code = "NetStream.Play.InsufficientBW"
+	NETSTREAM_PLAY_NOSUPPORTEDTRACKFOUND Code = "NetStream.Play.NoSupportedTrackFound"
+	NETSTREAM_PLAY_PUBLISHNOTIFY         Code = "NetStream.Play.PublishNotify"
+	NETSTREAM_PLAY_RESET                 Code = "NetStream.Play.Reset"
+	NETSTREAM_PLAY_START                 Code = "NetStream.Play.Start"
+	NETSTREAM_PLAY_STOP                  Code = "NetStream.Play.Stop"
+	NETSTREAM_PLAY_STREAMNOTFOUND        Code = "NetStream.Play.StreamNotFound"
+	NETSTREAM_PLAY_TRANSITION            Code = "NetStream.Play.Transition"
+	NETSTREAM_PLAY_UNPUBLISHNOTIFY       Code = "NetStream.Play.UnpublishNotify"
+	NETSTREAM_PUBLISH_BADNAME            Code = "NetStream.Publish.BadName"
+	NETSTREAM_PUBLISH_REJECTED           Code = "NetStream.Publish.Rejected"
+	NETSTREAM_PUBLISH_IDLE               Code = "NetStream.Publish.Idle"
+	NETSTREAM_PUBLISH_START              Code = "NetStream.Publish.Start"
+	NETSTREAM_RECORD_ALREADYEXISTS       Code = "NetStream.Record.AlreadyExists"
+	NETSTREAM_RECORD_FAILED              Code = "NetStream.Record.Failed"
+	NETSTREAM_RECORD_NOACCESS            Code = "NetStream.Record.NoAccess"
+	NETSTREAM_RECORD_START               Code = "NetStream.Record.Start"
+	NETSTREAM_RECORD_STOP                Code = "NetStream.Record.Stop"
+	NETSTREAM_SEEK_FAILED                Code = "NetStream.Seek.Failed"
+	NETSTREAM_SEEK_INVALIDTIME           Code = "NetStream.Seek.InvalidTime"
+	NETSTREAM_SEEK_NOTIFY                Code = "NetStream.Seek.Notify"
+	NETSTREAM_STEP_NOTIFY                Code = "NetStream.Step.Notify"
+	NETSTREAM_UNPAUSE_NOTIFY             Code = "NetStream.Unpause.Notify"
+	NETSTREAM_UNPUBLISH_SUCCESS          Code = "NetStream.Unpublish.Success"
+	NETSTREAM_VIDEO_DIMENSIONCHANGE      Code = "NetStream.Video.DimensionChange"
 )
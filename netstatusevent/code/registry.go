@@ -0,0 +1,165 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/oddengine/events/netstatusevent/level"
+)
+
+// Category is the object which a Code is about.
+type Category string
+
+// NetStatusEvent categories.
+const (
+	NETCONNECTION Category = "NetConnection"
+	NETGROUP      Category = "NetGroup"
+	NETSTREAM     Category = "NetStream"
+)
+
+// Info describes a registered Code.
+type Info struct {
+	Level    level.Level
+	Category Category
+	// Description is a fmt format, which is applied to the arguments given to the constructor, if any.
+	Description string
+}
+
+// Describe formats the description with the arguments, if any.
+func (me Info) Describe(args ...interface{}) string {
+	if len(args) == 0 {
+		return me.Description
+	}
+	return fmt.Sprintf(me.Description, args...)
+}
+
+var (
+	mtx      sync.RWMutex
+	registry = make(map[Code]Info)
+)
+
+// Register adds the code with its info to the registry, and is meant to be called at init time.
+// It panics if the code is already registered, or the info lacks level or category.
+func Register(c Code, info Info) {
+	if c == "" || info.Level == "" || info.Category == "" {
+		panic(fmt.Sprintf("incomplete code registration: code=%s, info=%+v", c, info))
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	if _, ok := registry[c]; ok {
+		panic(fmt.Sprintf("code already registered: %s", c))
+	}
+	registry[c] = info
+}
+
+// Lookup returns the info of the code, and whether it is registered.
+func Lookup(c Code) (Info, bool) {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	info, ok := registry[c]
+	return info, ok
+}
+
+// Defined returns whether the code is registered.
+func Defined(c Code) bool {
+	_, ok := Lookup(c)
+	return ok
+}
+
+// Codes returns all of the registered codes.
+func Codes() []Code {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	codes := make([]Code, 0, len(registry))
+	for c := range registry {
+		codes = append(codes, c)
+	}
+	return codes
+}
+
+func init() {
+	for _, r := range []struct {
+		code        Code
+		level       level.Level
+		description string
+	}{
+		{NETCONNECTION_CALL_FAILED, level.ERROR, "The call failed."},
+		{NETCONNECTION_CALL_SUCCESS, level.STATUS, "The call succeeded."},
+		{NETCONNECTION_CONNECT_APPSHUTDOWN, level.ERROR, "The application is shutting down."},
+		{NETCONNECTION_CONNECT_BADGATEWAY, level.ERROR, "The gateway returned an invalid response."},
+		{NETCONNECTION_CONNECT_CLOSED, level.STATUS, "The connection was closed successfully."},
+		{NETCONNECTION_CONNECT_FAILED, level.ERROR, "The connection attempt failed."},
+		{NETCONNECTION_CONNECT_GATEWAYTIMEOUT, level.ERROR, "The gateway timed out."},
+		{NETCONNECTION_CONNECT_IDLETIMEOUT, level.STATUS, "The connection was closed after being idle too long."},
+		{NETCONNECTION_CONNECT_INVALIDAPP, level.ERROR, "The application name specified is invalid."},
+		{NETCONNECTION_CONNECT_NETWORKCHANGE, level.STATUS, "The network has changed."},
+		{NETCONNECTION_CONNECT_REJECTED, level.ERROR, "The connection attempt did not have permission to access the application."},
+		{NETCONNECTION_CONNECT_SUCCESS, level.STATUS, "The connection attempt succeeded."},
+
+		{NETGROUP_CONNECT_CLOSED, level.STATUS, "The group was closed."},
+		{NETGROUP_CONNECT_FAILED, level.ERROR, "The group connection attempt failed."},
+		{NETGROUP_CONNECT_REJECTED, level.ERROR, "The group connection attempt did not have permission."},
+		{NETGROUP_CONNECT_SUCCESS, level.STATUS, "The group connection attempt succeeded."},
+		{NETGROUP_LOCALCOVERAGE_NOTIFY, level.STATUS, "The portion of the group address space for which this node is responsible has changed."},
+		{NETGROUP_MULTICASTSTREAM_PUBLISHNOTIFY, level.STATUS, "A new named stream is detected in the group."},
+		{NETGROUP_MULTICASTSTREAM_UNPUBLISHNOTIFY, level.STATUS, "A named stream is no longer available in the group."},
+		{NETGROUP_NEIGHBOR_CONNECT, level.STATUS, "A neighbor connected to this node."},
+		{NETGROUP_NEIGHBOR_DISCONNECT, level.STATUS, "A neighbor disconnected from this node."},
+		{NETGROUP_POSTING_FAILED, level.ERROR, "The posting failed."},
+		{NETGROUP_POSTING_NOTIFY, level.STATUS, "A new group posting is received."},
+		{NETGROUP_REPLICATION_FETCH_FAILED, level.STATUS, "The fetch request for an object failed or was denied."},
+		{NETGROUP_REPLICATION_FETCH_RESULT, level.STATUS, "The fetch request was satisfied by a neighbor."},
+		{NETGROUP_REPLICATION_FETCH_SENDNOTIFY, level.STATUS, "The fetch request was sent to a neighbor."},
+		{NETGROUP_REPLICATION_REQUEST, level.STATUS, "A neighbor requested an object."},
+		{NETGROUP_SENDTO_FAILED, level.ERROR, "The message could not be routed."},
+		{NETGROUP_SENDTO_NOTIFY, level.STATUS, "A message directed to this node is received."},
+
+		{NETSTREAM_BUFFER_EMPTY, level.STATUS, "The buffer is empty, and data is not being received quickly enough to fill it."},
+		{NETSTREAM_BUFFER_FLUSH, level.STATUS, "The stream has finished, and the buffer will be emptied."},
+		{NETSTREAM_BUFFER_FULL, level.STATUS, "The buffer is full, and the stream begins playing."},
+		{NETSTREAM_FAILED, level.ERROR, "An error has occurred for a reason other than those listed in other codes."},
+		{NETSTREAM_INVOKE_FAILED, level.ERROR, "The invocation failed."},
+		{NETSTREAM_INVOKE_START, level.STATUS, "The invocation started."},
+		{NETSTREAM_INVOKE_STOP, level.STATUS, "The invocation stopped."},
+		{NETSTREAM_INVOKE_TIMEOUT, level.ERROR, "The invocation timed out."},
+		{NETSTREAM_PAUSE_NOTIFY, level.STATUS, "The stream is paused."},
+		{NETSTREAM_PLAY_FAILED, level.ERROR, "An error has occurred in playback."},
+		{NETSTREAM_PLAY_FILESTRUCTUREINVALID, level.ERROR, "The file structure is invalid."},
+		{NETSTREAM_PLAY_INSUFFICIENTBW, level.WARNING, "The client does not have sufficient bandwidth to play the data at normal speed."},
+		{NETSTREAM_PLAY_NOSUPPORTEDTRACKFOUND, level.ERROR, "No supported tracks are found."},
+		{NETSTREAM_PLAY_PUBLISHNOTIFY, level.STATUS, "The initial publish to a stream is sent to all subscribers."},
+		{NETSTREAM_PLAY_RESET, level.STATUS, "The playlist has reset."},
+		{NETSTREAM_PLAY_START, level.STATUS, "Playback has started."},
+		{NETSTREAM_PLAY_STOP, level.STATUS, "Playback has stopped."},
+		{NETSTREAM_PLAY_STREAMNOTFOUND, level.ERROR, "The stream passed to the play method can't be found."},
+		{NETSTREAM_PLAY_TRANSITION, level.STATUS, "The stream is switching to another one."},
+		{NETSTREAM_PLAY_UNPUBLISHNOTIFY, level.STATUS, "An unpublish from a stream is sent to all subscribers."},
+		{NETSTREAM_PUBLISH_BADNAME, level.ERROR, "Attempt to publish a stream which is already being published by someone else."},
+		{NETSTREAM_PUBLISH_REJECTED, level.ERROR, "The publish attempt did not have permission."},
+		{NETSTREAM_PUBLISH_IDLE, level.STATUS, "The publisher of the stream is idle and not transmitting data."},
+		{NETSTREAM_PUBLISH_START, level.STATUS, "Publish was successful."},
+		{NETSTREAM_RECORD_ALREADYEXISTS, level.STATUS, "The stream being recorded maps to a file that is already being recorded to by another stream."},
+		{NETSTREAM_RECORD_FAILED, level.ERROR, "An attempt to record a stream failed."},
+		{NETSTREAM_RECORD_NOACCESS, level.ERROR, "Attempt to record a stream that is still playing or the client has no access right."},
+		{NETSTREAM_RECORD_START, level.STATUS, "Recording has started."},
+		{NETSTREAM_RECORD_STOP, level.STATUS, "Recording stopped."},
+		{NETSTREAM_SEEK_FAILED, level.ERROR, "The seek fails, which happens if the stream is not seekable."},
+		{NETSTREAM_SEEK_INVALIDTIME, level.ERROR, "The seek time is beyond the end of the downloaded data."},
+		{NETSTREAM_SEEK_NOTIFY, level.STATUS, "The seek operation is complete."},
+		{NETSTREAM_STEP_NOTIFY, level.STATUS, "The step operation is complete."},
+		{NETSTREAM_UNPAUSE_NOTIFY, level.STATUS, "The stream is resumed."},
+		{NETSTREAM_UNPUBLISH_SUCCESS, level.STATUS, "The unpublish operation was successful."},
+		{NETSTREAM_VIDEO_DIMENSIONCHANGE, level.STATUS, "The video dimensions are available or have changed."},
+	} {
+		Register(r.code, Info{
+			Level:       r.level,
+			Category:    Category(string(r.code)[:strings.IndexByte(string(r.code), '.')]),
+			Description: r.description,
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package code
+
+import (
+	"testing"
+
+	"github.com/oddengine/events/netstatusevent/level"
+)
+
+func expectPanic(t *testing.T, name string, fn func()) {
+	t.Helper()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("%s should panic", name)
+		}
+	}()
+	fn()
+}
+
+const NETSTREAM_TEST_REGISTER Code = "NetStream.Test.Register"
+
+func init() {
+	Register(NETSTREAM_TEST_REGISTER, Info{Level: level.ERROR, Category: NETSTREAM, Description: "Stream %s failed."})
+}
+
+func TestRegister(t *testing.T) {
+	c := NETSTREAM_TEST_REGISTER
+	info, ok := Lookup(c)
+	if !ok || !Defined(c) || info.Level != level.ERROR || info.Category != NETSTREAM {
+		t.Fatalf("Lookup(%s) = %+v, %v", c, info, ok)
+	}
+	if s := info.Describe("foo"); s != "Stream foo failed." {
+		t.Fatalf("Describe() = %q", s)
+	}
+
+	found := false
+	for _, registered := range Codes() {
+		found = found || registered == c
+	}
+	if !found {
+		t.Fatalf("Codes() lacks %s", c)
+	}
+
+	expectPanic(t, "duplicate code", func() {
+		Register(c, Info{Level: level.STATUS, Category: NETSTREAM})
+	})
+	expectPanic(t, "built-in code", func() {
+		Register(NETSTREAM_PLAY_START, Info{Level: level.STATUS, Category: NETSTREAM})
+	})
+	expectPanic(t, "empty code", func() {
+		Register("", Info{Level: level.STATUS, Category: NETSTREAM})
+	})
+	expectPanic(t, "missing level", func() {
+		Register("NetStream.Test.Level", Info{Category: NETSTREAM})
+	})
+	expectPanic(t, "missing category", func() {
+		Register("NetStream.Test.Category", Info{Level: level.STATUS})
+	})
+	if Defined("NetStream.Test.Level") || Defined("NetStream.Test.Category") {
+		t.Fatalf("incomplete code registered")
+	}
+}
+
+func TestBuiltinCodes(t *testing.T) {
+	info, ok := Lookup(NETGROUP_POSTING_FAILED)
+	if !ok || info.Level != level.ERROR || info.Category != NETGROUP || info.Describe() != "The posting failed." {
+		t.Fatalf("Lookup(%s) = %+v, %v", NETGROUP_POSTING_FAILED, info, ok)
+	}
+	if Defined("NetStream.Test.Undefined") {
+		t.Fatalf("undefined code reported as defined")
+	}
+}
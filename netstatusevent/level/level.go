@@ -1,8 +1,11 @@
 package level
 
+// Level is the severity of a NetStatusEvent.
+type Level string
+
 // NetStatusEvent levels
 const (
-	ERROR   = "error"
-	STATUS  = "status"
-	WARNING = "warning"
+	ERROR   Level = "error"
+	STATUS  Level = "status"
+	WARNING Level = "warning"
 )
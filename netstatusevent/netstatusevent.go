@@ -5,6 +5,8 @@ import (
 
 	"github.com/oddengine/events"
 	Event "github.com/oddengine/events/event"
+	"github.com/oddengine/events/netstatusevent/code"
+	"github.com/oddengine/events/netstatusevent/level"
 )
 
 // NetStatusEvent types.
@@ -13,27 +15,46 @@ const (
 )
 
 // NetStatusEvent dispatched when a net status event occurred.
+// Besides the listeners of NET_STATUS, it is delivered to the listeners of its code, e.g. code.NETSTREAM_PLAY_START,
+// and of the matching namespaces, e.g. "NetStream.Play.*".
 type NetStatusEvent struct {
 	Event.Event
-	Level       string
-	Code        string
+	Level       level.Level
+	Code        code.Code
 	Description string
 	Info        map[string]interface{}
 }
 
 // Init this class
-func (me *NetStatusEvent) Init(event string, level string, code string, description string, info map[string]interface{}) *NetStatusEvent {
-	me.Event.Init(event)
-	me.Level = level
-	me.Code = code
+func (me *NetStatusEvent) Init(event string, lvl level.Level, c code.Code, description string, info map[string]interface{}, options ...Event.EventOptions) *NetStatusEvent {
+	me.Event.Init(event, options...)
+	me.Level = lvl
+	me.Code = c
 	me.Description = description
 	me.Info = info
 	return me
 }
 
+// SubType returns the code, so that the event is also delivered to the listeners of the code.
+func (me *NetStatusEvent) SubType() string {
+	return string(me.Code)
+}
+
+// Validate returns an error if the code is not registered, or the level is not the registered one.
+func (me *NetStatusEvent) Validate() error {
+	info, ok := code.Lookup(me.Code)
+	if !ok {
+		return fmt.Errorf("undefined code: %s", me.Code)
+	}
+	if me.Level != info.Level {
+		return fmt.Errorf("unexpected level of code %s: %s, expected %s", me.Code, me.Level, info.Level)
+	}
+	return nil
+}
+
 // Clone an instance of an NetStatusEvent subclass.
 func (me *NetStatusEvent) Clone() events.IEvent {
-	return New(me.Type(), me.Target(), me.Level, me.Code, me.Description, me.Info)
+	return New(me.Type(), me.Target(), me.Level, me.Code, me.Description, me.Info, me.Options())
 }
 
 // String returns a string containing all the properties of the NetStatusEvent object.
@@ -42,9 +63,34 @@ func (me *NetStatusEvent) String() string {
 }
 
 // New creates a new NetStatusEvent object.
-func New(event string, target events.IEventTarget, level string, code string, description string, info map[string]interface{}) *NetStatusEvent {
-	e := new(NetStatusEvent).Init(event, level, code, description, info)
+func New(event string, target events.IEventTarget, lvl level.Level, c code.Code, description string, info map[string]interface{}, options ...Event.EventOptions) *NetStatusEvent {
+	e := new(NetStatusEvent).Init(event, lvl, c, description, info, options...)
 	e.SetTarget(target)
 	e.SetCurrentTarget(target)
 	return e
 }
+
+// NewWithCode creates a new NET_STATUS event, whose level and description are filled in from the code registry.
+// It panics if the code is not registered.
+func NewWithCode(target events.IEventTarget, c code.Code, info map[string]interface{}, options ...Event.EventOptions) *NetStatusEvent {
+	return newWithCode(target, c, info, nil, options...)
+}
+
+// NewWithCodef creates a new NET_STATUS event like NewWithCode, and formats the registered description with the
+// arguments, e.g. the stream name of a code registered with "Stream %s not found.".
+func NewWithCodef(target events.IEventTarget, c code.Code, info map[string]interface{}, options Event.EventOptions, args ...interface{}) *NetStatusEvent {
+	return newWithCode(target, c, info, args, options)
+}
+
+func newWithCode(target events.IEventTarget, c code.Code, info map[string]interface{}, args []interface{}, options ...Event.EventOptions) *NetStatusEvent {
+	registered, ok := code.Lookup(c)
+	if !ok {
+		panic(fmt.Sprintf("undefined code: %s", c))
+	}
+	return New(NET_STATUS, target, registered.Level, c, registered.Describe(args...), info, options...)
+}
+
+// OnCode registers the handler with the target for the code, and returns the EventListener to remove it later.
+func OnCode(target events.IEventTarget, c code.Code, handler func(*NetStatusEvent), options ...events.EventListenerOptions) *events.EventListener {
+	return events.On(target, string(c), handler, options...)
+}
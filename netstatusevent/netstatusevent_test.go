@@ -0,0 +1,104 @@
+package netstatusevent_test
+
+import (
+	"testing"
+
+	"github.com/oddengine/events"
+	Event "github.com/oddengine/events/event"
+	"github.com/oddengine/events/netstatusevent"
+	"github.com/oddengine/events/netstatusevent/code"
+	"github.com/oddengine/events/netstatusevent/level"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Trace(s string)                                      {}
+func (nopLogger) Tracef(format string, args ...interface{})           {}
+func (nopLogger) Debug(n uint32, s string)                            {}
+func (nopLogger) Debugf(n uint32, format string, args ...interface{}) {}
+func (nopLogger) Info(s string)                                       {}
+func (nopLogger) Infof(format string, args ...interface{})            {}
+func (nopLogger) Warn(s string)                                       {}
+func (nopLogger) Warnf(format string, args ...interface{})            {}
+func (nopLogger) Error(s string)                                      {}
+func (nopLogger) Errorf(format string, args ...interface{})           {}
+
+const NETSTREAM_TEST_DENIED code.Code = "NetStream.Test.Denied"
+
+func init() {
+	code.Register(NETSTREAM_TEST_DENIED, code.Info{
+		Level:       level.ERROR,
+		Category:    code.NETSTREAM,
+		Description: "Playback of %s is denied.",
+	})
+}
+
+func TestNewWithCode(t *testing.T) {
+	target := new(events.EventTarget).Init(nopLogger{})
+
+	e := netstatusevent.NewWithCode(target, code.NETSTREAM_PLAY_START, nil, Event.EventOptions{Bubbles: true})
+	if e.Type() != netstatusevent.NET_STATUS || e.Level != level.STATUS || e.Description != "Playback has started." || !e.Bubbles() {
+		t.Fatalf("unexpected event: %v", e)
+	}
+
+	e = netstatusevent.NewWithCodef(target, NETSTREAM_TEST_DENIED, nil, Event.EventOptions{}, "foo")
+	if e.Level != level.ERROR || e.Description != "Playback of foo is denied." {
+		t.Fatalf("unexpected event: %v", e)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("undefined code should panic")
+		}
+	}()
+	netstatusevent.NewWithCode(target, "NetStream.Test.Undefined", nil)
+}
+
+func TestValidate(t *testing.T) {
+	target := new(events.EventTarget).Init(nopLogger{})
+
+	if err := netstatusevent.NewWithCode(target, code.NETSTREAM_PLAY_FAILED, nil).Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	if err := netstatusevent.New(netstatusevent.NET_STATUS, target, level.STATUS, code.NETSTREAM_PLAY_FAILED, "", nil).Validate(); err == nil {
+		t.Fatalf("Validate() accepted the wrong level")
+	}
+	if err := netstatusevent.New(netstatusevent.NET_STATUS, target, level.STATUS, "NetStream.Test.Undefined", "", nil).Validate(); err == nil {
+		t.Fatalf("Validate() accepted an undefined code")
+	}
+}
+
+func TestOnCode(t *testing.T) {
+	var got []string
+	target := new(events.EventTarget).Init(nopLogger{})
+	record := func(tag string) func(*netstatusevent.NetStatusEvent) {
+		return func(e *netstatusevent.NetStatusEvent) {
+			got = append(got, tag+":"+string(e.Code))
+		}
+	}
+	events.On(target, netstatusevent.NET_STATUS, record("type"))
+	netstatusevent.OnCode(target, code.NETSTREAM_PLAY_START, record("code"))
+	events.On(target, "NetStream.Play.*", record("namespace"))
+	listener := netstatusevent.OnCode(target, code.NETSTREAM_SEEK_NOTIFY, record("code"))
+
+	target.DispatchEvent(netstatusevent.NewWithCode(target, code.NETSTREAM_PLAY_START, nil))
+	target.DispatchEvent(netstatusevent.NewWithCode(target, code.NETSTREAM_PLAY_STOP, nil))
+	target.DispatchEvent(netstatusevent.NewWithCode(target, code.NETSTREAM_SEEK_NOTIFY, nil))
+	target.RemoveEventListener(string(code.NETSTREAM_SEEK_NOTIFY), listener)
+	target.DispatchEvent(netstatusevent.NewWithCode(target, code.NETSTREAM_SEEK_NOTIFY, nil))
+
+	want := []string{
+		"type:NetStream.Play.Start", "code:NetStream.Play.Start", "namespace:NetStream.Play.Start",
+		"type:NetStream.Play.Stop", "namespace:NetStream.Play.Stop",
+		"type:NetStream.Seek.Notify", "code:NetStream.Seek.Notify",
+		"type:NetStream.Seek.Notify",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
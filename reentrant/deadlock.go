@@ -0,0 +1,382 @@
+package reentrant
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/oddengine/events/clock"
+)
+
+// DeadlockKind describes what a DeadlockError reports.
+type DeadlockKind int
+
+// DeadlockKind enum.
+const (
+	DeadlockTimeout DeadlockKind = iota
+	LockOrderInversion
+)
+
+// DeadlockOptions configures the deadlock diagnostics of a mutex.
+//
+// A mutex without options uses the package globals if DEBUG_DEADLOCK is on, which
+// enables both the timeout and the lock order checking. The options should not be
+// changed while the mutex is locked.
+type DeadlockOptions struct {
+	// Timeout reports a DeadlockTimeout if the lock is not acquired in time. Zero
+	// disables the timeout.
+	Timeout time.Duration
+	// Clock measures the timeout. Nil means the real clock.
+	Clock clock.Clock
+	// LockOrder records the order of the locks held by each owner into a global graph
+	// across all mutexes, and reports a LockOrderInversion when two locks are acquired
+	// in the opposite order of a previous acquisition.
+	LockOrder bool
+	// Handler is called with the report. Nil means to panic with it.
+	Handler func(err *DeadlockError)
+}
+
+func (me *DeadlockOptions) enabled() bool {
+	return me.Timeout > 0 || me.LockOrder
+}
+
+func (me *DeadlockOptions) report(err *DeadlockError) {
+	if me.Handler == nil {
+		panic(err)
+	}
+	me.Handler(err)
+}
+
+// A LockSite records an acquisition of a lock.
+type LockSite struct {
+	Lock  interface{}
+	Owner Owner
+	Read  bool
+	Stack []byte
+}
+
+func newLockSite(lock interface{}, owner int64, read bool) *LockSite {
+	return &LockSite{
+		Lock:  lock,
+		Owner: Owner(owner),
+		Read:  read,
+		Stack: debug.Stack(),
+	}
+}
+
+func (me *LockSite) String() string {
+	who := fmt.Sprintf("goroutine %d", me.Owner)
+	if me.Owner < 0 {
+		who = fmt.Sprintf("owner %d", me.Owner)
+	}
+	mode := ""
+	if me.Read {
+		mode = " for reading"
+	}
+	return fmt.Sprintf("%s locking %p%s at:\n%s", who, me.Lock, mode, me.Stack)
+}
+
+// A LockOrder records that a lock was acquired while holding another one.
+type LockOrder struct {
+	Before LockSite
+	After  LockSite
+}
+
+// DeadlockError reports both sides of a potential deadlock.
+//
+// For a DeadlockTimeout, Acquiring is the acquisition which has been waiting, Holding is
+// the one of the current writer, or nil if unknown, and Readers are the ones of the current
+// readers of a RWMutex. For a LockOrderInversion, Acquiring is out of order while Holding
+// is held by the same owner, and Cycle is the chain of previous acquisitions which leads
+// from the lock of Acquiring back to the lock of Holding, closing the cycle.
+type DeadlockError struct {
+	Kind      DeadlockKind
+	Timeout   time.Duration
+	Acquiring *LockSite
+	Holding   *LockSite
+	Readers   []*LockSite
+	Cycle     []*LockOrder
+}
+
+func (me *DeadlockError) Error() string {
+	var b strings.Builder
+
+	switch me.Kind {
+	case DeadlockTimeout:
+		fmt.Fprintf(&b, "deadlock timeout %p: waiting for more than %v\n\n%s", me.Acquiring.Lock, me.Timeout, me.Acquiring)
+		if me.Holding != nil {
+			fmt.Fprintf(&b, "\nwhile held by %s", me.Holding)
+		}
+		for _, reader := range me.Readers {
+			fmt.Fprintf(&b, "\nwhile held by %s", reader)
+		}
+		if me.Holding == nil && len(me.Readers) == 0 {
+			fmt.Fprintf(&b, "\nwhile held by unknown owner")
+		}
+	case LockOrderInversion:
+		fmt.Fprintf(&b, "lock order inversion of %p and %p\n\n", me.Holding.Lock, me.Acquiring.Lock)
+		fmt.Fprintf(&b, "after %s\nthen %s", me.Holding, me.Acquiring)
+		for _, order := range me.Cycle {
+			fmt.Fprintf(&b, "\nwhile previously after %s\nthen %s", &order.Before, &order.After)
+		}
+	}
+	return b.String()
+}
+
+// deadlockState is the deadlock diagnostics state of a mutex.
+type deadlockState struct {
+	options atomic.Pointer[DeadlockOptions]
+	holder  atomic.Pointer[LockSite]
+
+	mtx     sync.Mutex
+	readers map[Owner]*LockSite
+}
+
+// config returns the options of the mutex, or nil if the diagnostics are disabled.
+func (me *deadlockState) config() *DeadlockOptions {
+	if options := me.options.Load(); options != nil {
+		if options.enabled() {
+			return options
+		}
+		return nil
+	}
+	if DEBUG_DEADLOCK {
+		return &DeadlockOptions{
+			Timeout:   DEADLOCK_TIMEOUT,
+			Clock:     DEADLOCK_CLOCK,
+			LockOrder: true,
+		}
+	}
+	return nil
+}
+
+// begin starts diagnosing an acquisition, or returns nil if the diagnostics are disabled.
+func (me *deadlockState) begin(lock interface{}, owner int64, read bool) *deadlockWatch {
+	options := me.config()
+	if options == nil {
+		return nil
+	}
+	return &deadlockWatch{
+		state:   me,
+		options: options,
+		site:    newLockSite(lock, owner, read),
+	}
+}
+
+// releaseWriter forgets the holder of the exclusive lock.
+func (me *deadlockState) releaseWriter() {
+	if holder := me.holder.Swap(nil); holder != nil {
+		lockOrder.released(holder.Lock, holder.Owner)
+	}
+}
+
+// releaseReader forgets a read lock of the owner.
+func (me *deadlockState) releaseReader(lock interface{}, owner int64) {
+	if me.config() == nil {
+		return
+	}
+
+	me.mtx.Lock()
+	delete(me.readers, Owner(owner))
+	me.mtx.Unlock()
+
+	lockOrder.released(lock, Owner(owner))
+}
+
+// acquiredReader records the site of a read lock.
+func (me *deadlockState) acquiredReader(site *LockSite) {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	if me.readers == nil {
+		me.readers = make(map[Owner]*LockSite)
+	}
+	me.readers[site.Owner] = site
+}
+
+// readerSites returns the sites of the current read locks.
+func (me *deadlockState) readerSites() []*LockSite {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	sites := make([]*LockSite, 0, len(me.readers))
+	for _, site := range me.readers {
+		sites = append(sites, site)
+	}
+	return sites
+}
+
+// deadlockWatch diagnoses an acquisition. The methods do nothing on a nil receiver.
+type deadlockWatch struct {
+	state   *deadlockState
+	options *DeadlockOptions
+	site    *LockSite
+	done    chan struct{}
+}
+
+// wait checks the lock order, and starts the timer of the timeout, before blocking.
+func (me *deadlockWatch) wait() {
+	if me == nil {
+		return
+	}
+	if me.options.LockOrder {
+		if err := lockOrder.check(me.site); err != nil {
+			me.options.report(err)
+		}
+	}
+	if me.options.Timeout > 0 {
+		me.done = make(chan struct{})
+		go me.watch(me.done)
+	}
+}
+
+func (me *deadlockWatch) watch(done chan struct{}) {
+	t := clock.Default(me.options.Clock).NewTimer(me.options.Timeout)
+	defer t.Stop()
+
+	select {
+	case <-done:
+	case <-t.C():
+		me.options.report(&DeadlockError{
+			Kind:      DeadlockTimeout,
+			Timeout:   me.options.Timeout,
+			Acquiring: me.site,
+			Holding:   me.state.holder.Load(),
+			Readers:   me.state.readerSites(),
+		})
+	}
+}
+
+// acquired stops the timer, and records the holder.
+func (me *deadlockWatch) acquired(exclusive bool) {
+	if me == nil {
+		return
+	}
+	me.abandoned()
+	if exclusive {
+		me.state.holder.Store(me.site)
+	} else {
+		me.state.acquiredReader(me.site)
+	}
+	if me.options.LockOrder {
+		lockOrder.acquired(me.site)
+	}
+}
+
+// abandoned stops the timer.
+func (me *deadlockWatch) abandoned() {
+	if me == nil || me.done == nil {
+		return
+	}
+	close(me.done)
+	me.done = nil
+}
+
+// lockOrderGraph records the locks held by each owner, and the order of acquisitions.
+// The locks in the graph are never released, so it is meant for debugging only.
+type lockOrderGraph struct {
+	mtx   sync.Mutex
+	held  map[Owner][]*LockSite
+	edges map[lockPair]*LockOrder
+	next  map[interface{}][]*LockOrder
+}
+
+type lockPair struct {
+	before interface{}
+	after  interface{}
+}
+
+var lockOrder lockOrderGraph
+
+// check records the order of the acquisition after the locks held by the same owner,
+// and returns an error if any of them was acquired after this lock before, directly or
+// through a chain of other locks.
+func (me *lockOrderGraph) check(site *LockSite) *DeadlockError {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	if me.edges == nil {
+		me.edges = make(map[lockPair]*LockOrder)
+		me.next = make(map[interface{}][]*LockOrder)
+	}
+	for _, held := range me.held[site.Owner] {
+		if held.Lock == site.Lock {
+			continue
+		}
+		if cycle := me.path(site.Lock, held.Lock); cycle != nil {
+			return &DeadlockError{
+				Kind:      LockOrderInversion,
+				Acquiring: site,
+				Holding:   held,
+				Cycle:     cycle,
+			}
+		}
+		pair := lockPair{held.Lock, site.Lock}
+		if _, ok := me.edges[pair]; !ok {
+			order := &LockOrder{Before: *held, After: *site}
+			me.edges[pair] = order
+			me.next[held.Lock] = append(me.next[held.Lock], order)
+		}
+	}
+	return nil
+}
+
+// path returns the shortest chain of acquisitions from one lock to another, or nil if
+// there is none.
+func (me *lockOrderGraph) path(from, to interface{}) []*LockOrder {
+	via := map[interface{}]*LockOrder{from: nil}
+	queue := []interface{}{from}
+	for len(queue) > 0 {
+		lock := queue[0]
+		queue = queue[1:]
+		for _, order := range me.next[lock] {
+			after := order.After.Lock
+			if _, ok := via[after]; ok {
+				continue
+			}
+			via[after] = order
+			if after != to {
+				queue = append(queue, after)
+				continue
+			}
+
+			var cycle []*LockOrder
+			for step := via[to]; step != nil; step = via[step.Before.Lock] {
+				cycle = append([]*LockOrder{step}, cycle...)
+			}
+			return cycle
+		}
+	}
+	return nil
+}
+
+func (me *lockOrderGraph) acquired(site *LockSite) {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	if me.held == nil {
+		me.held = make(map[Owner][]*LockSite)
+	}
+	me.held[site.Owner] = append(me.held[site.Owner], site)
+}
+
+func (me *lockOrderGraph) released(lock interface{}, owner Owner) {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	held := me.held[owner]
+	for i := len(held) - 1; i >= 0; i-- {
+		if held[i].Lock == lock {
+			held = append(held[:i], held[i+1:]...)
+			break
+		}
+	}
+	if len(held) == 0 {
+		delete(me.held, owner)
+	} else {
+		me.held[owner] = held
+	}
+}
@@ -0,0 +1,198 @@
+package reentrant
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oddengine/events/clock/clocktest"
+)
+
+func TestLockOrderInversion(t *testing.T) {
+	var (
+		a, b RWMutex
+		errs []*DeadlockError
+	)
+
+	options := DeadlockOptions{
+		LockOrder: true,
+		Handler: func(err *DeadlockError) {
+			errs = append(errs, err)
+		},
+	}
+	a.SetDeadlockOptions(options)
+	b.SetDeadlockOptions(options)
+
+	a.Lock()
+	b.RLock()
+	b.RUnlock()
+	a.Unlock()
+	if len(errs) != 0 {
+		t.Fatalf("unexpected report: %v", errs[0])
+	}
+
+	b.RLock()
+	a.Lock()
+	a.Unlock()
+	b.RUnlock()
+	if len(errs) != 1 {
+		t.Fatalf("got %d reports, want 1", len(errs))
+	}
+
+	err := errs[0]
+	if err.Kind != LockOrderInversion || err.Holding.Lock != &b || err.Acquiring.Lock != &a ||
+		len(err.Cycle) != 1 || err.Cycle[0].Before.Lock != &a || err.Cycle[0].After.Lock != &b {
+		t.Fatalf("unexpected report: %v", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "TestLockOrderInversion") || !strings.Contains(msg, "for reading") {
+		t.Fatalf("report without stacks: %s", msg)
+	}
+}
+
+func TestLockOrderCycle(t *testing.T) {
+	var (
+		a, b, c Mutex
+		errs    []*DeadlockError
+	)
+
+	options := DeadlockOptions{
+		LockOrder: true,
+		Handler: func(err *DeadlockError) {
+			errs = append(errs, err)
+		},
+	}
+	a.SetDeadlockOptions(options)
+	b.SetDeadlockOptions(options)
+	c.SetDeadlockOptions(options)
+
+	lockPair := func(first, second *Mutex) {
+		first.Lock()
+		second.Lock()
+		second.Unlock()
+		first.Unlock()
+	}
+	lockPair(&a, &b)
+	lockPair(&b, &c)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected report: %v", errs[0])
+	}
+
+	lockPair(&c, &a)
+	if len(errs) != 1 {
+		t.Fatalf("got %d reports, want 1", len(errs))
+	}
+
+	err := errs[0]
+	if err.Kind != LockOrderInversion || err.Holding.Lock != &c || err.Acquiring.Lock != &a || len(err.Cycle) != 2 ||
+		err.Cycle[0].Before.Lock != &a || err.Cycle[0].After.Lock != &b ||
+		err.Cycle[1].Before.Lock != &b || err.Cycle[1].After.Lock != &c {
+		t.Fatalf("unexpected report: %v", err)
+	}
+	if msg := err.Error(); strings.Count(msg, "while previously after") != 2 {
+		t.Fatalf("report without the whole cycle: %s", msg)
+	}
+}
+
+func TestDeadlockTimeout(t *testing.T) {
+	var mtx Mutex
+
+	clk := clocktest.NewManualClock(time.Now())
+	c := make(chan *DeadlockError, 1)
+	mtx.SetDeadlockOptions(DeadlockOptions{
+		Timeout: time.Second,
+		Clock:   clk,
+		Handler: func(err *DeadlockError) {
+			c <- err
+		},
+	})
+
+	mtx.Lock()
+	holder := CurrentOwner()
+
+	done := make(chan Owner)
+	go func() {
+		mtx.Lock()
+		mtx.Unlock()
+		done <- CurrentOwner()
+	}()
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+
+	err := <-c
+	mtx.Unlock()
+	waiter := <-done
+
+	if err.Kind != DeadlockTimeout || err.Holding == nil || err.Holding.Owner != holder || err.Acquiring.Owner != waiter {
+		t.Fatalf("unexpected report: %v", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "deadlock timeout") || !strings.Contains(msg, "TestDeadlockTimeout") {
+		t.Fatalf("unexpected report: %s", msg)
+	}
+}
+
+func TestDeadlockTimeoutWithReaders(t *testing.T) {
+	var mtx RWMutex
+
+	clk := clocktest.NewManualClock(time.Now())
+	c := make(chan *DeadlockError, 1)
+	mtx.SetDeadlockOptions(DeadlockOptions{
+		Timeout: time.Second,
+		Clock:   clk,
+		Handler: func(err *DeadlockError) {
+			c <- err
+		},
+	})
+
+	reader := NewOwner()
+	mtx.RLockAs(reader)
+	mtx.RLock()
+	self := CurrentOwner()
+
+	done := make(chan struct{})
+	go func() {
+		mtx.Lock()
+		mtx.Unlock()
+		close(done)
+	}()
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+
+	err := <-c
+	mtx.RUnlockAs(reader)
+	mtx.RUnlock()
+	<-done
+
+	if err.Kind != DeadlockTimeout || err.Holding != nil || len(err.Readers) != 2 {
+		t.Fatalf("unexpected report: %v", err)
+	}
+	owners := map[Owner]bool{}
+	for _, site := range err.Readers {
+		if !site.Read || site.Lock != &mtx {
+			t.Fatalf("unexpected reader: %v", site)
+		}
+		owners[site.Owner] = true
+	}
+	if !owners[reader] || !owners[self] {
+		t.Fatalf("unexpected readers: %v", err.Readers)
+	}
+	if msg := err.Error(); strings.Contains(msg, "unknown owner") || !strings.Contains(msg, "TestDeadlockTimeoutWithReaders") {
+		t.Fatalf("report without the readers: %s", msg)
+	}
+}
+
+func TestDeadlockOptionsOverrideGlobals(t *testing.T) {
+	DEBUG_DEADLOCK = true
+	defer func() {
+		DEBUG_DEADLOCK = false
+	}()
+
+	var mtx Mutex
+	if mtx.deadlock.config() == nil {
+		t.Fatalf("diagnostics disabled with DEBUG_DEADLOCK on")
+	}
+
+	mtx.SetDeadlockOptions(DeadlockOptions{})
+	if mtx.deadlock.config() != nil {
+		t.Fatalf("diagnostics enabled with empty options")
+	}
+}
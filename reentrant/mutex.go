@@ -1,10 +1,13 @@
 package reentrant
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/oddengine/events/clock"
 )
 
 // Static constants.
@@ -12,9 +15,15 @@ const (
 	MAX_RECURSION int32 = 0
 )
 
+// Deadlock diagnostics of the mutexes without DeadlockOptions.
 var (
 	DEBUG_DEADLOCK   = false
 	DEADLOCK_TIMEOUT = 5 * time.Second
+	DEADLOCK_CLOCK   clock.Clock
+
+	// STRICT_UNLOCK makes Unlock check the owner, and panic on an Unlock by any other
+	// than the owner, or on an Unlock of an unlocked mutex.
+	STRICT_UNLOCK = false
 )
 
 // A Mutex is a reentrant mutual exclusion lock.
@@ -24,8 +33,15 @@ var (
 type Mutex struct {
 	sync.Mutex
 
-	goid      int64
-	recursion int32
+	owner     atomic.Int64
+	recursion atomic.Int32
+	deadlock  deadlockState
+}
+
+// SetDeadlockOptions configures the deadlock diagnostics of this Mutex, overriding the
+// package globals.
+func (me *Mutex) SetDeadlockOptions(options DeadlockOptions) {
+	me.deadlock.options.Store(&options)
 }
 
 // Lock locks this Mutex.
@@ -33,48 +49,165 @@ type Mutex struct {
 // If the lock is already in use by the calling goroutine, it only checks the
 // recursion. Otherwise, the calling goroutine blocks until the mutex is available.
 func (me *Mutex) Lock() {
-	self := GetCurrentGoroutineID()
-	if atomic.LoadInt64(&me.goid) == self {
-		me.recursion++
-		if MAX_RECURSION > 0 && me.recursion > MAX_RECURSION {
-			panic(fmt.Sprintf("max recursion reached: %d", me.recursion))
-		}
+	me.LockAs(CurrentOwner())
+}
+
+// LockAs locks this Mutex on behalf of the owner, without looking up the goroutine ID.
+//
+// If the lock is already in use by the owner, it only checks the recursion. Otherwise,
+// the calling goroutine blocks until the mutex is available. An explicit owner must not
+// be used by multiple goroutines at the same time.
+func (me *Mutex) LockAs(owner Owner) {
+	self := int64(owner)
+	if me.owner.Load() == self {
+		me.reenter()
 		return
 	}
 
-	var c chan bool
-	if DEBUG_DEADLOCK {
-		c = make(chan bool)
-		go func(c chan bool) {
-			t := time.NewTicker(DEADLOCK_TIMEOUT)
-			defer func() {
-				t.Stop()
-				close(c)
-			}()
-
-			select {
-			case <-c:
-			case <-t.C:
-				panic(fmt.Errorf("deadlock timeout %p", me))
-			}
-		}(c)
-	}
+	w := me.deadlock.begin(me, self, false)
+	w.wait()
 	me.Mutex.Lock()
-	if DEBUG_DEADLOCK {
-		c <- true
+	me.acquired(self)
+	w.acquired(true)
+}
+
+// TryLock tries to lock this Mutex and reports whether it succeeded. It succeeds if the
+// lock is already in use by the calling goroutine.
+func (me *Mutex) TryLock() bool {
+	return me.TryLockAs(CurrentOwner())
+}
+
+// TryLockAs tries to lock this Mutex on behalf of the owner and reports whether it
+// succeeded.
+func (me *Mutex) TryLockAs(owner Owner) bool {
+	self := int64(owner)
+	if me.owner.Load() == self {
+		me.reenter()
+		return true
+	}
+	if !me.Mutex.TryLock() {
+		return false
+	}
+	me.acquired(self)
+	me.deadlock.begin(me, self, false).acquired(true)
+	return true
+}
+
+// LockTimeout locks this Mutex like Lock, but gives up after the duration, and reports
+// whether it succeeded.
+func (me *Mutex) LockTimeout(d time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	return me.LockContext(ctx) == nil
+}
+
+// LockContext locks this Mutex like Lock, but gives up when the context is done, and
+// returns the error of the context. It locks on behalf of the owner of the context set
+// by WithOwner, if any, otherwise the calling goroutine.
+//
+// While waiting, the lock is acquired by another goroutine, which keeps waiting after
+// the context is done, and releases the lock at once when acquired.
+func (me *Mutex) LockContext(ctx context.Context) error {
+	owner, ok := OwnerFromContext(ctx)
+	if !ok {
+		owner = CurrentOwner()
+	}
+	if me.TryLockAs(owner) {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	w := me.deadlock.begin(me, int64(owner), false)
+	w.wait()
+
+	c := make(chan bool)
+	go func() {
+		me.Mutex.Lock()
+		select {
+		case c <- true:
+		case <-ctx.Done():
+			me.Mutex.Unlock()
+		}
+	}()
+
+	select {
+	case <-c:
+		me.acquired(int64(owner))
+		w.acquired(true)
+		return nil
+	case <-ctx.Done():
+		w.abandoned()
+		return ctx.Err()
 	}
-	atomic.StoreInt64(&me.goid, self)
-	me.recursion = 1
 }
 
 // Unlock unlocks this Mutex.
 //
-// It is a run-time error if this is not locked on entry to Unlock. A locked Mutex
-// is not associated with a particular goroutine. It is allowed for one goroutine
+// Unless STRICT_UNLOCK is on, an Unlock of an unlocked Mutex is ignored, and a locked
+// Mutex is not associated with a particular goroutine. It is allowed for one goroutine
 // to lock a Mutex and then arrange for another goroutine to unlock it.
 func (me *Mutex) Unlock() {
-	if atomic.AddInt32(&me.recursion, -1) == 0 {
-		atomic.StoreInt64(&me.goid, 0)
-		me.Mutex.Unlock()
+	if STRICT_UNLOCK {
+		me.UnlockAs(CurrentOwner())
+		return
+	}
+	me.unlock()
+}
+
+// UnlockAs unlocks this Mutex on behalf of the owner. It panics if STRICT_UNLOCK is on,
+// and the lock is not in use by the owner.
+func (me *Mutex) UnlockAs(owner Owner) {
+	if STRICT_UNLOCK {
+		checkOwner(me.owner.Load(), int64(owner))
+	}
+	me.unlock()
+}
+
+func (me *Mutex) unlock() {
+	for {
+		n := me.recursion.Load()
+		if n <= 0 {
+			if STRICT_UNLOCK {
+				panic("unlock of unlocked mutex")
+			}
+			// An excess Unlock is ignored, rather than corrupting the state.
+			return
+		}
+		if !me.recursion.CompareAndSwap(n, n-1) {
+			continue
+		}
+		if n == 1 {
+			me.deadlock.releaseWriter()
+			me.owner.Store(0)
+			me.Mutex.Unlock()
+		}
+		return
+	}
+}
+
+// reenter increases the recursion of the owner.
+func (me *Mutex) reenter() {
+	if n := me.recursion.Add(1); MAX_RECURSION > 0 && n > MAX_RECURSION {
+		panic(fmt.Sprintf("max recursion reached: %d", n))
+	}
+}
+
+// acquired records the owner of the lock.
+func (me *Mutex) acquired(self int64) {
+	me.owner.Store(self)
+	me.recursion.Store(1)
+}
+
+// checkOwner panics if the lock is not in use by the owner.
+func checkOwner(holder int64, self int64) {
+	switch holder {
+	case self:
+	case 0:
+		panic("unlock of unlocked mutex")
+	default:
+		panic(fmt.Sprintf("unlock of mutex held by %d from %d", holder, self))
 	}
 }
@@ -0,0 +1,260 @@
+package reentrant
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMutexReentrant(t *testing.T) {
+	var mtx Mutex
+
+	mtx.Lock()
+	mtx.Lock()
+	mtx.Unlock()
+	mtx.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		mtx.Lock()
+		mtx.Unlock()
+		close(done)
+	}()
+	<-done
+}
+
+func TestMutexContention(t *testing.T) {
+	var (
+		mtx   Mutex
+		wg    sync.WaitGroup
+		count int
+	)
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 200; j++ {
+				mtx.Lock()
+				mtx.Lock()
+				count++
+				mtx.Unlock()
+				mtx.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if count != 16*200 {
+		t.Fatalf("count = %d, want %d", count, 16*200)
+	}
+}
+
+func TestMutexLockAs(t *testing.T) {
+	var mtx Mutex
+
+	owner := NewOwner()
+	if owner == CurrentOwner() || owner == NewOwner() {
+		t.Fatalf("owner is not unique: %d", owner)
+	}
+
+	mtx.LockAs(owner)
+	done := make(chan struct{})
+	go func() {
+		// The owner is passed to another goroutine, which reenters the lock.
+		mtx.LockAs(owner)
+		mtx.Unlock()
+		close(done)
+	}()
+	<-done
+	mtx.Unlock()
+
+	mtx.Lock()
+	mtx.Unlock()
+}
+
+func TestMutexTryLock(t *testing.T) {
+	var mtx Mutex
+
+	if !mtx.TryLock() || !mtx.TryLock() {
+		t.Fatalf("TryLock failed on an unlocked or owned mutex")
+	}
+
+	c := make(chan bool)
+	go func() {
+		c <- mtx.TryLock()
+	}()
+	if <-c {
+		t.Fatalf("TryLock succeeded on a mutex locked by another goroutine")
+	}
+
+	mtx.Unlock()
+	mtx.Unlock()
+	go func() {
+		ok := mtx.TryLock()
+		if ok {
+			mtx.Unlock()
+		}
+		c <- ok
+	}()
+	if !<-c {
+		t.Fatalf("TryLock failed on an unlocked mutex")
+	}
+}
+
+func TestMutexLockTimeout(t *testing.T) {
+	var mtx Mutex
+
+	mtx.Lock()
+	if !mtx.LockTimeout(time.Millisecond) {
+		t.Fatalf("LockTimeout failed on an owned mutex")
+	}
+	mtx.Unlock()
+
+	c := make(chan bool)
+	go func() {
+		c <- mtx.LockTimeout(10 * time.Millisecond)
+	}()
+	if <-c {
+		t.Fatalf("LockTimeout succeeded on a mutex locked by another goroutine")
+	}
+
+	go func() {
+		ok := mtx.LockTimeout(time.Second)
+		if ok {
+			mtx.Unlock()
+		}
+		c <- ok
+	}()
+	time.Sleep(10 * time.Millisecond)
+	mtx.Unlock()
+	if !<-c {
+		t.Fatalf("LockTimeout failed after the mutex was unlocked")
+	}
+
+	// The abandoned attempt must have released the lock.
+	if !mtx.LockTimeout(time.Second) {
+		t.Fatalf("mutex is still locked by an abandoned attempt")
+	}
+	mtx.Unlock()
+}
+
+func TestMutexLockContext(t *testing.T) {
+	var mtx Mutex
+
+	owner := NewOwner()
+	mtx.LockAs(owner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := make(chan error)
+	go func() {
+		c <- mtx.LockContext(ctx)
+	}()
+	cancel()
+	if err := <-c; !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Reentered by the owner carried by the context, from another goroutine.
+	go func() {
+		c <- mtx.LockContext(WithOwner(context.Background(), owner))
+	}()
+	if err := <-c; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mtx.Unlock()
+	mtx.Unlock()
+
+	if err := mtx.LockContext(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mtx.Unlock()
+}
+
+func TestMutexExcessUnlock(t *testing.T) {
+	var mtx Mutex
+
+	mtx.Unlock()
+	mtx.Lock()
+	mtx.Unlock()
+	mtx.Unlock()
+
+	// The excess Unlocks did not corrupt the state.
+	mtx.Lock()
+	c := make(chan bool)
+	go func() {
+		c <- mtx.TryLock()
+	}()
+	if <-c {
+		t.Fatalf("TryLock succeeded on a mutex locked by another goroutine")
+	}
+	mtx.Unlock()
+}
+
+func TestMutexStrictUnlock(t *testing.T) {
+	STRICT_UNLOCK = true
+	defer func() {
+		STRICT_UNLOCK = false
+	}()
+
+	var mtx Mutex
+	expectPanic(t, "unlock of unlocked mutex", mtx.Unlock)
+
+	mtx.Lock()
+	mtx.Lock()
+	c := make(chan interface{})
+	go func() {
+		defer func() {
+			c <- recover()
+		}()
+		mtx.Unlock()
+	}()
+	if x := <-c; x == nil {
+		t.Fatalf("Unlock by another goroutine should panic")
+	}
+	mtx.Unlock()
+	mtx.Unlock()
+	expectPanic(t, "unlock of unlocked mutex", mtx.Unlock)
+
+	owner := NewOwner()
+	mtx.LockAs(owner)
+	expectPanic(t, "Unlock without the explicit owner", mtx.Unlock)
+	mtx.UnlockAs(owner)
+}
+
+func expectPanic(t *testing.T, name string, fn func()) {
+	t.Helper()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("%s should panic", name)
+		}
+	}()
+	fn()
+}
+
+func BenchmarkMutex(b *testing.B) {
+	var mtx Mutex
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			mtx.Lock()
+			mtx.Unlock()
+		}
+	})
+}
+
+func BenchmarkMutexLockAs(b *testing.B) {
+	var mtx Mutex
+
+	b.RunParallel(func(pb *testing.PB) {
+		owner := NewOwner()
+		for pb.Next() {
+			mtx.LockAs(owner)
+			mtx.Unlock()
+		}
+	})
+}
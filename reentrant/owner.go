@@ -0,0 +1,39 @@
+package reentrant
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+var ownerSeq atomic.Int64
+
+type ownerKey struct{}
+
+// An Owner identifies the holder of a Mutex.
+//
+// The owner of a goroutine is its ID, which is expensive to look up. An explicit owner
+// created by NewOwner can be passed around instead, e.g. by a single-threaded loop, so
+// that locking does not need to look up the goroutine ID at all.
+type Owner int64
+
+// NewOwner returns a new explicit owner, which never equals the owner of any goroutine.
+func NewOwner() Owner {
+	return Owner(ownerSeq.Add(-1))
+}
+
+// CurrentOwner returns the owner of the calling goroutine.
+func CurrentOwner() Owner {
+	return Owner(GetCurrentGoroutineID())
+}
+
+// WithOwner returns a copy of the context carrying the owner, which LockContext locks on
+// behalf of.
+func WithOwner(ctx context.Context, owner Owner) context.Context {
+	return context.WithValue(ctx, ownerKey{}, owner)
+}
+
+// OwnerFromContext returns the owner carried by the context, if any.
+func OwnerFromContext(ctx context.Context) (Owner, bool) {
+	owner, ok := ctx.Value(ownerKey{}).(Owner)
+	return owner, ok
+}
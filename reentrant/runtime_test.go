@@ -0,0 +1,29 @@
+package reentrant
+
+import (
+	"testing"
+)
+
+func TestGetCurrentGoroutineID(t *testing.T) {
+	self := GetCurrentGoroutineID()
+	if self <= 0 {
+		t.Fatalf("invalid goroutine id: %d", self)
+	}
+	if GetCurrentGoroutineID() != self {
+		t.Fatalf("goroutine id changed")
+	}
+
+	c := make(chan int64)
+	go func() {
+		c <- GetCurrentGoroutineID()
+	}()
+	if other := <-c; other == self || other <= 0 {
+		t.Fatalf("unexpected goroutine id of another goroutine: %d", other)
+	}
+}
+
+func BenchmarkGetCurrentGoroutineID(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		GetCurrentGoroutineID()
+	}
+}
@@ -0,0 +1,258 @@
+package reentrant
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// A RWMutex is a reentrant reader/writer mutual exclusion lock.
+//
+// The lock can be held by an arbitrary number of readers or a single writer, and both
+// can be locked again by their owner. The rules are:
+//   - A reader can lock for reading again, even if a writer is waiting.
+//   - A writer can lock for reading or writing again, and the nested locks count as
+//     the write lock, which is released by the last Unlock or RUnlock.
+//   - A reader can not upgrade to a writer, since two readers upgrading at the same
+//     time would deadlock. Lock panics if the owner holds a read lock.
+//
+// The zero value for a RWMutex is an unlocked mutex. And a RWMutex must not be copied
+// after first use.
+type RWMutex struct {
+	sync.RWMutex
+
+	writer    atomic.Int64
+	recursion atomic.Int32
+
+	mtx     sync.Mutex
+	readers map[int64]int32
+
+	deadlock deadlockState
+}
+
+// SetDeadlockOptions configures the deadlock diagnostics of this RWMutex, overriding
+// the package globals.
+func (me *RWMutex) SetDeadlockOptions(options DeadlockOptions) {
+	me.deadlock.options.Store(&options)
+}
+
+// Lock locks this RWMutex for writing.
+//
+// If the lock is already held for writing by the calling goroutine, it only checks
+// the recursion. Otherwise, the calling goroutine blocks until the lock is available.
+func (me *RWMutex) Lock() {
+	me.LockAs(CurrentOwner())
+}
+
+// LockAs locks this RWMutex for writing on behalf of the owner, without looking up the
+// goroutine ID.
+func (me *RWMutex) LockAs(owner Owner) {
+	self := int64(owner)
+	if me.writer.Load() == self {
+		me.reenter()
+		return
+	}
+	if me.reading(self) > 0 {
+		panic("read lock can not be upgraded to write lock")
+	}
+
+	w := me.deadlock.begin(me, self, false)
+	w.wait()
+	me.RWMutex.Lock()
+	me.writer.Store(self)
+	me.recursion.Store(1)
+	w.acquired(true)
+}
+
+// TryLock tries to lock this RWMutex for writing and reports whether it succeeded. It
+// succeeds if the lock is already held for writing by the calling goroutine, and fails
+// if the calling goroutine holds a read lock.
+func (me *RWMutex) TryLock() bool {
+	self := GetCurrentGoroutineID()
+	if me.writer.Load() == self {
+		me.reenter()
+		return true
+	}
+	if me.reading(self) > 0 || !me.RWMutex.TryLock() {
+		return false
+	}
+	me.writer.Store(self)
+	me.recursion.Store(1)
+	me.deadlock.begin(me, self, false).acquired(true)
+	return true
+}
+
+// Unlock unlocks this RWMutex for writing.
+//
+// An Unlock of a RWMutex which is not locked for writing is ignored. If STRICT_UNLOCK is
+// on, it panics unless the calling goroutine is the writer.
+func (me *RWMutex) Unlock() {
+	if STRICT_UNLOCK {
+		me.UnlockAs(CurrentOwner())
+		return
+	}
+	me.unlock()
+}
+
+// UnlockAs unlocks this RWMutex for writing on behalf of the owner. It panics if
+// STRICT_UNLOCK is on, and the owner is not the writer.
+func (me *RWMutex) UnlockAs(owner Owner) {
+	if STRICT_UNLOCK {
+		checkOwner(me.writer.Load(), int64(owner))
+	}
+	me.unlock()
+}
+
+func (me *RWMutex) unlock() {
+	for {
+		n := me.recursion.Load()
+		if n <= 0 {
+			if STRICT_UNLOCK {
+				panic("unlock of unlocked mutex")
+			}
+			// An excess Unlock is ignored, rather than corrupting the state.
+			return
+		}
+		if !me.recursion.CompareAndSwap(n, n-1) {
+			continue
+		}
+		if n == 1 {
+			me.deadlock.releaseWriter()
+			me.writer.Store(0)
+			me.RWMutex.Unlock()
+		}
+		return
+	}
+}
+
+// RLock locks this RWMutex for reading.
+//
+// If the lock is already held by the calling goroutine, it only checks the recursion.
+// Otherwise, the calling goroutine blocks until the lock is available for reading.
+func (me *RWMutex) RLock() {
+	me.RLockAs(CurrentOwner())
+}
+
+// RLockAs locks this RWMutex for reading on behalf of the owner, without looking up
+// the goroutine ID.
+func (me *RWMutex) RLockAs(owner Owner) {
+	self := int64(owner)
+	if me.writer.Load() == self {
+		me.reenter()
+		return
+	}
+
+	me.mtx.Lock()
+	if n := me.readers[self]; n > 0 {
+		n++
+		me.readers[self] = n
+		me.mtx.Unlock()
+
+		if MAX_RECURSION > 0 && n > MAX_RECURSION {
+			panic(fmt.Sprintf("max recursion reached: %d", n))
+		}
+		return
+	}
+	me.mtx.Unlock()
+
+	w := me.deadlock.begin(me, self, true)
+	w.wait()
+	me.RWMutex.RLock()
+
+	me.mtx.Lock()
+	if me.readers == nil {
+		me.readers = make(map[int64]int32)
+	}
+	me.readers[self] = 1
+	me.mtx.Unlock()
+	w.acquired(false)
+}
+
+// TryRLock tries to lock this RWMutex for reading and reports whether it succeeded. It
+// succeeds if the lock is already held by the calling goroutine.
+func (me *RWMutex) TryRLock() bool {
+	self := GetCurrentGoroutineID()
+	if me.writer.Load() == self {
+		me.reenter()
+		return true
+	}
+
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	if n := me.readers[self]; n > 0 {
+		me.readers[self] = n + 1
+		return true
+	}
+	if !me.RWMutex.TryRLock() {
+		return false
+	}
+	if me.readers == nil {
+		me.readers = make(map[int64]int32)
+	}
+	me.readers[self] = 1
+	me.deadlock.begin(me, self, true).acquired(false)
+	return true
+}
+
+// RUnlock undoes a single RLock call.
+//
+// A RUnlock by a goroutine which does not hold a read lock is ignored. If STRICT_UNLOCK
+// is on, it panics.
+func (me *RWMutex) RUnlock() {
+	me.RUnlockAs(CurrentOwner())
+}
+
+// RUnlockAs undoes a single RLockAs call of the owner.
+func (me *RWMutex) RUnlockAs(owner Owner) {
+	self := int64(owner)
+
+	me.mtx.Lock()
+	n, ok := me.readers[self]
+	if ok {
+		if n--; n > 0 {
+			me.readers[self] = n
+		} else {
+			delete(me.readers, self)
+		}
+	}
+	me.mtx.Unlock()
+
+	switch {
+	case ok && n > 0:
+	case ok:
+		me.deadlock.releaseReader(me, self)
+		me.RWMutex.RUnlock()
+	case me.writer.Load() == self:
+		// A read lock nested in the write lock.
+		me.unlock()
+	case STRICT_UNLOCK:
+		panic(fmt.Sprintf("runlock of mutex not read locked by %d", self))
+	}
+}
+
+// RLocker returns a Locker interface that implements the Lock and Unlock methods by
+// calling RLock and RUnlock of this RWMutex.
+func (me *RWMutex) RLocker() sync.Locker {
+	return (*rlocker)(me)
+}
+
+// reenter increases the recursion of the writer.
+func (me *RWMutex) reenter() {
+	if n := me.recursion.Add(1); MAX_RECURSION > 0 && n > MAX_RECURSION {
+		panic(fmt.Sprintf("max recursion reached: %d", n))
+	}
+}
+
+// reading returns the read recursion of the owner.
+func (me *RWMutex) reading(self int64) int32 {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	return me.readers[self]
+}
+
+type rlocker RWMutex
+
+func (me *rlocker) Lock()   { (*RWMutex)(me).RLock() }
+func (me *rlocker) Unlock() { (*RWMutex)(me).RUnlock() }
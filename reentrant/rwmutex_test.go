@@ -0,0 +1,191 @@
+package reentrant
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRWMutexReentrant(t *testing.T) {
+	var mtx RWMutex
+
+	mtx.RLock()
+	mtx.RLock()
+	mtx.RUnlock()
+	mtx.RUnlock()
+
+	mtx.Lock()
+	mtx.Lock()
+	mtx.RLock()
+	mtx.RUnlock()
+	mtx.Unlock()
+	mtx.Unlock()
+
+	// Released by the last RUnlock.
+	mtx.Lock()
+	mtx.RLock()
+	mtx.Unlock()
+	mtx.RUnlock()
+
+	done := make(chan struct{})
+	go func() {
+		mtx.Lock()
+		mtx.Unlock()
+		close(done)
+	}()
+	<-done
+}
+
+func TestRWMutexReadReentrantWithWaitingWriter(t *testing.T) {
+	var mtx RWMutex
+
+	mtx.RLock()
+	done := make(chan struct{})
+	go func() {
+		mtx.Lock()
+		mtx.Unlock()
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// A sync.RWMutex would deadlock here, since the writer is waiting.
+	mtx.RLock()
+	mtx.RUnlock()
+	mtx.RUnlock()
+	<-done
+}
+
+func TestRWMutexUpgrade(t *testing.T) {
+	var mtx RWMutex
+
+	mtx.RLock()
+	defer mtx.RUnlock()
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("upgrade from read to write lock should panic")
+		}
+	}()
+	mtx.Lock()
+}
+
+func TestRWMutexTryLock(t *testing.T) {
+	var mtx RWMutex
+
+	if !mtx.TryRLock() || !mtx.TryRLock() {
+		t.Fatalf("TryRLock failed on an unlocked or read mutex")
+	}
+	if mtx.TryLock() {
+		t.Fatalf("TryLock upgraded a read lock")
+	}
+	mtx.RUnlock()
+	mtx.RUnlock()
+
+	if !mtx.TryLock() || !mtx.TryLock() || !mtx.TryRLock() {
+		t.Fatalf("TryLock failed on an unlocked or owned mutex")
+	}
+	c := make(chan bool)
+	go func() {
+		c <- mtx.TryRLock()
+	}()
+	if <-c {
+		t.Fatalf("TryRLock succeeded on a mutex locked by another goroutine")
+	}
+	mtx.RUnlock()
+	mtx.Unlock()
+	mtx.Unlock()
+}
+
+func TestRWMutexExcessUnlock(t *testing.T) {
+	var mtx RWMutex
+
+	mtx.Unlock()
+	mtx.RUnlock()
+	mtx.RLock()
+	mtx.RUnlock()
+	mtx.RUnlock()
+
+	mtx.Lock()
+	c := make(chan bool)
+	go func() {
+		c <- mtx.TryRLock()
+	}()
+	if <-c {
+		t.Fatalf("TryRLock succeeded on a mutex locked by another goroutine")
+	}
+	mtx.Unlock()
+}
+
+func TestRWMutexStrictUnlock(t *testing.T) {
+	STRICT_UNLOCK = true
+	defer func() {
+		STRICT_UNLOCK = false
+	}()
+
+	var mtx RWMutex
+	expectPanic(t, "unlock of unlocked mutex", mtx.Unlock)
+	expectPanic(t, "runlock of unlocked mutex", mtx.RUnlock)
+
+	mtx.Lock()
+	mtx.RLock()
+	c := make(chan interface{})
+	go func() {
+		defer func() {
+			c <- recover()
+		}()
+		mtx.Unlock()
+	}()
+	if x := <-c; x == nil {
+		t.Fatalf("Unlock by another goroutine should panic")
+	}
+	mtx.RUnlock()
+	mtx.Unlock()
+	expectPanic(t, "excess Unlock", mtx.Unlock)
+}
+
+func TestRWMutexContention(t *testing.T) {
+	var (
+		mtx   RWMutex
+		wg    sync.WaitGroup
+		count int
+	)
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 200; j++ {
+				if i%4 == 0 {
+					mtx.Lock()
+					mtx.RLock()
+					count++
+					mtx.RUnlock()
+					mtx.Unlock()
+				} else {
+					mtx.RLock()
+					mtx.RLock()
+					_ = count
+					mtx.RUnlock()
+					mtx.RUnlock()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if count != 4*200 {
+		t.Fatalf("count = %d, want %d", count, 4*200)
+	}
+}
+
+func BenchmarkRWMutexRLockAs(b *testing.B) {
+	var mtx RWMutex
+
+	b.RunParallel(func(pb *testing.PB) {
+		owner := NewOwner()
+		for pb.Next() {
+			mtx.RLockAs(owner)
+			mtx.RUnlockAs(owner)
+		}
+	})
+}
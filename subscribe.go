@@ -0,0 +1,138 @@
+package events
+
+import (
+	"sync"
+)
+
+// OverflowPolicy decides what happens when an event arrives at a full subscription channel.
+type OverflowPolicy int
+
+const (
+	// The dispatch blocks until the event is received, or the subscription is canceled.
+	BlockOnOverflow OverflowPolicy = iota
+	// The oldest event in the channel is dropped.
+	DropOldestOnOverflow
+	// The arriving event is dropped.
+	DropNewestOnOverflow
+)
+
+// Static constants.
+const (
+	SUBSCRIPTION_SIZE = 64
+)
+
+// SubscriptionOptions specifies characteristics about the subscription channel.
+type SubscriptionOptions struct {
+	// Size of the channel buffer, SUBSCRIPTION_SIZE if it is not positive.
+	Size     int
+	Overflow OverflowPolicy
+}
+
+type subscription struct {
+	mtx      sync.Mutex
+	c        chan IEvent
+	done     chan struct{}
+	overflow OverflowPolicy
+	pending  int
+	canceled bool
+}
+
+func (me *subscription) deliver(e IEvent) {
+	// The event keeps changing while it travels through the event flow, so a clone is sent to the subscriber.
+	e = e.Clone()
+
+	me.mtx.Lock()
+	if me.canceled {
+		me.mtx.Unlock()
+		return
+	}
+
+	switch me.overflow {
+	case DropOldestOnOverflow:
+		for {
+			select {
+			case me.c <- e:
+				me.mtx.Unlock()
+				return
+			default:
+			}
+			select {
+			case <-me.c:
+			default:
+			}
+		}
+
+	case DropNewestOnOverflow:
+		select {
+		case me.c <- e:
+		default:
+		}
+		me.mtx.Unlock()
+
+	default:
+		me.pending++
+		me.mtx.Unlock()
+
+		select {
+		case me.c <- e:
+		case <-me.done:
+		}
+
+		me.mtx.Lock()
+		me.pending--
+		if me.canceled && me.pending == 0 {
+			close(me.c)
+		}
+		me.mtx.Unlock()
+	}
+}
+
+func (me *subscription) cancel() {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	if me.canceled {
+		return
+	}
+	me.canceled = true
+	close(me.done)
+	if me.pending == 0 {
+		close(me.c)
+	}
+}
+
+// Subscribe delivers clones of the events of the types dispatched on the target to a buffered channel, blocking the
+// dispatch while it is full. The cancel function removes the listener, and closes the channel.
+func Subscribe(target IEventTarget, types ...string) (<-chan IEvent, func()) {
+	return SubscribeWithOptions(target, SubscriptionOptions{}, types...)
+}
+
+// SubscribeWithOptions is like Subscribe, with the channel size and overflow policy in the options.
+func SubscribeWithOptions(target IEventTarget, options SubscriptionOptions, types ...string) (<-chan IEvent, func()) {
+	size := options.Size
+	if size <= 0 {
+		size = SUBSCRIPTION_SIZE
+	}
+
+	sub := &subscription{
+		c:        make(chan IEvent, size),
+		done:     make(chan struct{}),
+		overflow: options.Overflow,
+	}
+	listener := NewEventListener(sub.deliver)
+	for _, event := range types {
+		target.AddEventListener(event, listener)
+	}
+
+	var once sync.Once
+	return sub.c, func() {
+		once.Do(func() {
+			// Cancel first, so that the dispatches blocked on a full channel return, and the ones still holding a snapshot
+			// with the listener deliver nothing.
+			sub.cancel()
+			for _, event := range types {
+				target.RemoveEventListener(event, listener)
+			}
+		})
+	}
+}
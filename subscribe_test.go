@@ -0,0 +1,126 @@
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/oddengine/events"
+	Event "github.com/oddengine/events/event"
+)
+
+// receive returns the types of the events received from the channel, until it is empty or closed.
+func receive(c <-chan events.IEvent) []string {
+	var types []string
+	for {
+		select {
+		case e, ok := <-c:
+			if !ok {
+				return types
+			}
+			types = append(types, e.Type())
+		default:
+			return types
+		}
+	}
+}
+
+func dispatchAll(target *node, types ...string) {
+	for _, typ := range types {
+		target.DispatchEvent(Event.New(typ, target))
+	}
+}
+
+func TestSubscribeDropNewest(t *testing.T) {
+	n := newNode("n", nil)
+	c, cancel := events.SubscribeWithOptions(n, events.SubscriptionOptions{Size: 2, Overflow: events.DropNewestOnOverflow},
+		Event.OPEN, Event.CHANGE, Event.CLOSE)
+	defer cancel()
+
+	dispatchAll(n, Event.OPEN, Event.CHANGE, Event.CLOSE)
+	equal(t, receive(c), Event.OPEN, Event.CHANGE)
+}
+
+func TestSubscribeDropOldest(t *testing.T) {
+	n := newNode("n", nil)
+	c, cancel := events.SubscribeWithOptions(n, events.SubscriptionOptions{Size: 2, Overflow: events.DropOldestOnOverflow},
+		Event.OPEN, Event.CHANGE, Event.CLOSE)
+	defer cancel()
+
+	dispatchAll(n, Event.OPEN, Event.CHANGE, Event.CLOSE)
+	equal(t, receive(c), Event.CHANGE, Event.CLOSE)
+}
+
+func TestSubscribeBlock(t *testing.T) {
+	n := newNode("n", nil)
+	c, cancel := events.SubscribeWithOptions(n, events.SubscriptionOptions{Size: 1}, Event.OPEN, Event.CHANGE)
+	defer cancel()
+
+	dispatchAll(n, Event.OPEN)
+	done := make(chan struct{})
+	go func() {
+		dispatchAll(n, Event.CHANGE)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatalf("dispatch not blocked by a full subscription")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if e := <-c; e.Type() != Event.OPEN {
+		t.Fatalf("got %s, want %s", e.Type(), Event.OPEN)
+	}
+	<-done
+	equal(t, receive(c), Event.CHANGE)
+}
+
+func TestSubscribeCancelWhileBlocked(t *testing.T) {
+	n := newNode("n", nil)
+	c, cancel := events.SubscribeWithOptions(n, events.SubscriptionOptions{Size: 1}, Event.OPEN, Event.CHANGE)
+
+	dispatchAll(n, Event.OPEN)
+	done := make(chan struct{})
+	go func() {
+		dispatchAll(n, Event.CHANGE)
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// The pending dispatch is released, and the channel is closed after it.
+	cancel()
+	<-done
+	equal(t, receive(c), Event.OPEN)
+	if _, ok := <-c; ok {
+		t.Fatalf("channel not closed after cancel")
+	}
+}
+
+func TestSubscribeClosedAfterCancel(t *testing.T) {
+	n := newNode("n", nil)
+	c, cancel := events.Subscribe(n, Event.CHANGE)
+
+	dispatchAll(n, Event.CHANGE)
+	cancel()
+	cancel()
+	dispatchAll(n, Event.CHANGE)
+
+	equal(t, receive(c), Event.CHANGE)
+	if _, ok := <-c; ok {
+		t.Fatalf("channel not closed after cancel")
+	}
+}
+
+func TestSubscribeClone(t *testing.T) {
+	parent := newNode("parent", nil)
+	child := newNode("child", parent)
+	c, cancel := events.Subscribe(child, Event.CHANGE)
+	defer cancel()
+
+	e := Event.New(Event.CHANGE, child, Event.EventOptions{Bubbles: true})
+	go child.DispatchEvent(e)
+
+	got := <-c
+	if got == events.IEvent(e) || got.Target() != child || got.EventPhase() != events.NoPhase {
+		t.Fatalf("unexpected event: %v, phase=%v", got, got.EventPhase())
+	}
+}
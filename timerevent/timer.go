@@ -0,0 +1,191 @@
+package timerevent
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/oddengine/events"
+	"github.com/oddengine/events/clock"
+	"github.com/oddengine/log"
+)
+
+var (
+	// ErrInvalidDelay is the panic value of Init and SetDelay if the delay is not positive.
+	ErrInvalidDelay = errors.New("invalid timer delay")
+)
+
+// Timer dispatches a TIMER event every delay, and a COMPLETE event after the number of repetitions, if it is not zero.
+// The events are dispatched on the goroutine of the timer.
+type Timer struct {
+	events.EventTarget
+
+	mtx          sync.Mutex
+	clock        clock.Clock
+	delay        time.Duration
+	repeatCount  int
+	currentCount int
+	stop         chan struct{}
+}
+
+// Init this class.
+// It panics with ErrInvalidDelay if the delay is not positive.
+func (me *Timer) Init(delay time.Duration, repeatCount int, logger log.ILogger) *Timer {
+	checkDelay(delay)
+	me.EventTarget.Init(logger)
+	me.delay = delay
+	me.repeatCount = repeatCount
+	me.currentCount = 0
+	return me
+}
+
+// SetClock sets the clock which drives the timer, nil means the real one. It takes effect on the next start.
+func (me *Timer) SetClock(c clock.Clock) {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	me.clock = c
+}
+
+// Delay returns the delay between timer events.
+func (me *Timer) Delay() time.Duration {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	return me.delay
+}
+
+// SetDelay sets the delay between timer events. If the timer is running, it restarts with the new delay.
+// It panics with ErrInvalidDelay if the delay is not positive.
+func (me *Timer) SetDelay(delay time.Duration) {
+	checkDelay(delay)
+
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	me.delay = delay
+	if me.stop != nil {
+		me.stopLocked()
+		me.startLocked()
+	}
+}
+
+// RepeatCount returns the total number of times the timer is set to run, zero means infinitely.
+func (me *Timer) RepeatCount() int {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	return me.repeatCount
+}
+
+// SetRepeatCount sets the total number of times the timer is set to run, zero means infinitely.
+// If the timer is running and the current count already reached it, the timer stops.
+func (me *Timer) SetRepeatCount(repeatCount int) {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	me.repeatCount = repeatCount
+	if me.stop != nil && repeatCount > 0 && me.currentCount >= repeatCount {
+		me.stopLocked()
+	}
+}
+
+// CurrentCount returns the total number of times the timer has fired since it started at zero.
+func (me *Timer) CurrentCount() int {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	return me.currentCount
+}
+
+// Running returns whether the timer is running.
+func (me *Timer) Running() bool {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	return me.stop != nil
+}
+
+// Start starts the timer, if it is not already running.
+func (me *Timer) Start() {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	if me.stop == nil {
+		me.startLocked()
+	}
+}
+
+// Stop stops the timer. When Start is called after Stop, the timer continues from the current count.
+func (me *Timer) Stop() {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	if me.stop != nil {
+		me.stopLocked()
+	}
+}
+
+// Reset stops the timer, and sets the current count back to zero.
+func (me *Timer) Reset() {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	if me.stop != nil {
+		me.stopLocked()
+	}
+	me.currentCount = 0
+}
+
+func (me *Timer) startLocked() {
+	me.stop = make(chan struct{})
+	go me.run(clock.Default(me.clock).NewTicker(me.delay), me.stop)
+}
+
+func (me *Timer) stopLocked() {
+	close(me.stop)
+	me.stop = nil
+}
+
+func (me *Timer) run(ticker clock.Ticker, stop chan struct{}) {
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C():
+		}
+
+		me.mtx.Lock()
+		if me.stop != stop {
+			me.mtx.Unlock()
+			return
+		}
+		me.currentCount++
+		complete := me.repeatCount > 0 && me.currentCount >= me.repeatCount
+		if complete {
+			me.stopLocked()
+		}
+		me.mtx.Unlock()
+
+		me.DispatchEvent(New(TIMER, me))
+		if complete {
+			me.DispatchEvent(New(COMPLETE, me))
+			return
+		}
+	}
+}
+
+func checkDelay(delay time.Duration) {
+	if delay <= 0 {
+		panic(fmt.Errorf("%w: %v", ErrInvalidDelay, delay))
+	}
+}
+
+// NewTimer creates a new Timer object.
+// It panics with ErrInvalidDelay if the delay is not positive.
+func NewTimer(delay time.Duration, repeatCount int, logger log.ILogger) *Timer {
+	return new(Timer).Init(delay, repeatCount, logger)
+}
@@ -0,0 +1,187 @@
+package timerevent_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oddengine/events"
+	"github.com/oddengine/events/clock/clocktest"
+	"github.com/oddengine/events/timerevent"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Trace(s string)                                      {}
+func (nopLogger) Tracef(format string, args ...interface{})           {}
+func (nopLogger) Debug(n uint32, s string)                            {}
+func (nopLogger) Debugf(n uint32, format string, args ...interface{}) {}
+func (nopLogger) Info(s string)                                       {}
+func (nopLogger) Infof(format string, args ...interface{})            {}
+func (nopLogger) Warn(s string)                                       {}
+func (nopLogger) Warnf(format string, args ...interface{})            {}
+func (nopLogger) Error(s string)                                      {}
+func (nopLogger) Errorf(format string, args ...interface{})           {}
+
+func expectInvalidDelay(t *testing.T, fn func()) {
+	t.Helper()
+
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, timerevent.ErrInvalidDelay) {
+			t.Fatalf("unexpected panic: %v", err)
+		}
+	}()
+	fn()
+}
+
+func TestInvalidDelay(t *testing.T) {
+	expectInvalidDelay(t, func() {
+		timerevent.NewTimer(0, 1, nopLogger{})
+	})
+	expectInvalidDelay(t, func() {
+		timerevent.NewTimer(-time.Second, 1, nopLogger{})
+	})
+
+	timer := timerevent.NewTimer(time.Second, 1, nopLogger{})
+	expectInvalidDelay(t, func() {
+		timer.SetDelay(0)
+	})
+	if timer.Delay() != time.Second {
+		t.Fatalf("delay = %v, want %v", timer.Delay(), time.Second)
+	}
+}
+
+// newManualTimer returns a timer driven by a manual clock, and a channel receiving the types of the events it dispatches.
+func newManualTimer(delay time.Duration, repeatCount int) (*timerevent.Timer, *clocktest.ManualClock, chan string) {
+	clk := clocktest.NewManualClock(time.Now())
+	timer := timerevent.NewTimer(delay, repeatCount, nopLogger{})
+	timer.SetClock(clk)
+
+	c := make(chan string, 16)
+	handler := func(e *timerevent.TimerEvent) {
+		c <- e.Type()
+	}
+	events.On(timer, timerevent.TIMER, handler)
+	events.On(timer, timerevent.COMPLETE, handler)
+	return timer, clk, c
+}
+
+// tick advances the clock by d, and expects the timer to dispatch the events in order.
+func tick(t *testing.T, clk *clocktest.ManualClock, c chan string, d time.Duration, want ...string) {
+	t.Helper()
+
+	clk.Advance(d)
+	for _, typ := range want {
+		if got := <-c; got != typ {
+			t.Fatalf("got %s, want %s", got, typ)
+		}
+	}
+}
+
+func TestTimerRepeatCount(t *testing.T) {
+	timer, clk, c := newManualTimer(time.Second, 3)
+	timer.Start()
+	clk.BlockUntil(1)
+
+	tick(t, clk, c, time.Second, timerevent.TIMER)
+	tick(t, clk, c, time.Second, timerevent.TIMER)
+	tick(t, clk, c, time.Second, timerevent.TIMER, timerevent.COMPLETE)
+	clk.BlockUntil(0)
+
+	if timer.CurrentCount() != 3 || timer.Running() {
+		t.Fatalf("count = %d, running = %v", timer.CurrentCount(), timer.Running())
+	}
+}
+
+func TestTimerStopAndStart(t *testing.T) {
+	timer, clk, c := newManualTimer(time.Second, 0)
+	timer.Start()
+	clk.BlockUntil(1)
+	tick(t, clk, c, time.Second, timerevent.TIMER)
+
+	timer.Stop()
+	clk.BlockUntil(0)
+	clk.Advance(5 * time.Second)
+	if timer.CurrentCount() != 1 || timer.Running() {
+		t.Fatalf("count = %d, running = %v", timer.CurrentCount(), timer.Running())
+	}
+
+	// It continues from the current count.
+	timer.Start()
+	clk.BlockUntil(1)
+	tick(t, clk, c, time.Second, timerevent.TIMER)
+	if timer.CurrentCount() != 2 {
+		t.Fatalf("count = %d, want 2", timer.CurrentCount())
+	}
+	timer.Stop()
+	clk.BlockUntil(0)
+	if len(c) != 0 {
+		t.Fatalf("unexpected event: %s", <-c)
+	}
+}
+
+func TestTimerReset(t *testing.T) {
+	timer, clk, c := newManualTimer(time.Second, 2)
+	timer.Start()
+	clk.BlockUntil(1)
+	tick(t, clk, c, time.Second, timerevent.TIMER)
+
+	timer.Reset()
+	clk.BlockUntil(0)
+	if timer.CurrentCount() != 0 || timer.Running() {
+		t.Fatalf("count = %d, running = %v", timer.CurrentCount(), timer.Running())
+	}
+
+	timer.Start()
+	clk.BlockUntil(1)
+	tick(t, clk, c, time.Second, timerevent.TIMER)
+	tick(t, clk, c, time.Second, timerevent.TIMER, timerevent.COMPLETE)
+	if timer.CurrentCount() != 2 {
+		t.Fatalf("count = %d, want 2", timer.CurrentCount())
+	}
+}
+
+func TestTimerSetRepeatCount(t *testing.T) {
+	timer, clk, c := newManualTimer(time.Second, 0)
+	timer.Start()
+	clk.BlockUntil(1)
+	tick(t, clk, c, time.Second, timerevent.TIMER)
+	tick(t, clk, c, time.Second, timerevent.TIMER)
+
+	// Raising the repeat count keeps it running.
+	timer.SetRepeatCount(3)
+	if !timer.Running() {
+		t.Fatalf("timer stopped")
+	}
+	tick(t, clk, c, time.Second, timerevent.TIMER, timerevent.COMPLETE)
+	clk.BlockUntil(0)
+
+	// Lowering the repeat count to the current count stops it, without completing.
+	timer.Reset()
+	timer.SetRepeatCount(0)
+	timer.Start()
+	clk.BlockUntil(1)
+	tick(t, clk, c, time.Second, timerevent.TIMER)
+	timer.SetRepeatCount(1)
+	clk.BlockUntil(0)
+	if timer.CurrentCount() != 1 || timer.Running() || len(c) != 0 {
+		t.Fatalf("count = %d, running = %v, events = %d", timer.CurrentCount(), timer.Running(), len(c))
+	}
+}
+
+func TestTimerSetDelay(t *testing.T) {
+	timer, clk, c := newManualTimer(time.Second, 0)
+	timer.Start()
+	clk.BlockUntil(1)
+	tick(t, clk, c, time.Second, timerevent.TIMER)
+
+	// It restarts with the new delay.
+	timer.SetDelay(2 * time.Second)
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	tick(t, clk, c, time.Second, timerevent.TIMER)
+	if timer.CurrentCount() != 2 {
+		t.Fatalf("count = %d, want 2", timer.CurrentCount())
+	}
+	timer.Stop()
+}
@@ -19,14 +19,14 @@ type TimerEvent struct {
 }
 
 // Init this class.
-func (me *TimerEvent) Init(event string) *TimerEvent {
-	me.Event.Init(event)
+func (me *TimerEvent) Init(event string, options ...Event.EventOptions) *TimerEvent {
+	me.Event.Init(event, options...)
 	return me
 }
 
 // Clone an instance of an TimerEvent subclass.
 func (me *TimerEvent) Clone() events.IEvent {
-	return New(me.Type(), me.Target())
+	return New(me.Type(), me.Target(), me.Options())
 }
 
 // String returns a string containing all the properties of the TimerEvent object.
@@ -35,8 +35,8 @@ func (me *TimerEvent) String() string {
 }
 
 // New creates a new TimerEvent object.
-func New(event string, target events.IEventTarget) *TimerEvent {
-	e := new(TimerEvent).Init(event)
+func New(event string, target events.IEventTarget, options ...Event.EventOptions) *TimerEvent {
+	e := new(TimerEvent).Init(event, options...)
 	e.SetTarget(target)
 	e.SetCurrentTarget(target)
 	return e
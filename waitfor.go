@@ -0,0 +1,32 @@
+package events
+
+import (
+	"context"
+)
+
+// WaitFor blocks until an event of the type, which matches the filter if it is not nil, is dispatched on the target,
+// and returns a clone of it, since the event itself keeps changing while it travels through the event flow.
+// The listener is removed before it returns, either with the event, or with the error of the context once it is done.
+func WaitFor(ctx context.Context, target IEventTarget, event string, filter func(e IEvent) bool) (IEvent, error) {
+	c := make(chan IEvent, 1)
+	listener := NewEventListener(func(e IEvent) {
+		if filter != nil && !filter(e) {
+			return
+		}
+		// Only the first match is kept, the listener may be invoked again until it is removed.
+		select {
+		case c <- e.Clone():
+		default:
+		}
+	})
+
+	target.AddEventListener(event, listener)
+	defer target.RemoveEventListener(event, listener)
+
+	select {
+	case e := <-c:
+		return e, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
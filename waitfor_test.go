@@ -0,0 +1,178 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/oddengine/events"
+	Event "github.com/oddengine/events/event"
+)
+
+// dispatchUntil dispatches the events created by fn on the target repeatedly, until done is closed.
+func dispatchUntil(target events.IEventTarget, done chan struct{}, fn func() events.IEvent) {
+	for {
+		select {
+		case <-done:
+			return
+		default:
+			target.DispatchEvent(fn())
+		}
+	}
+}
+
+func TestWaitForBubblingEvent(t *testing.T) {
+	parent := newNode("parent", nil)
+	child := newNode("child", parent)
+	events.On(parent, Event.CHANGE, func(e *Event.Event) {})
+
+	done := make(chan struct{})
+	go dispatchUntil(child, done, func() events.IEvent {
+		return Event.New(Event.CHANGE, child, Event.EventOptions{Bubbles: true})
+	})
+
+	e, err := events.WaitFor(context.Background(), child, Event.CHANGE, nil)
+	close(done)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The event returned is not touched by the dispatch in progress.
+	if e.Type() != Event.CHANGE || e.Target() != child || e.EventPhase() != events.NoPhase {
+		t.Fatalf("unexpected event: %v, target=%v, phase=%v", e, e.Target(), e.EventPhase())
+	}
+	_ = e.CurrentTarget()
+}
+
+func TestWaitForFilter(t *testing.T) {
+	var calls int32
+	n := newNode("n", nil)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			n.DispatchEvent(Event.New(Event.CHANGE, n, Event.EventOptions{Cancelable: i%3 == 2}))
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	e, err := events.WaitFor(context.Background(), n, Event.CHANGE, func(e events.IEvent) bool {
+		atomic.AddInt32(&calls, 1)
+		return e.Cancelable()
+	})
+	close(done)
+	if err != nil || !e.Cancelable() {
+		t.Fatalf("unexpected result: %v, %v", e, err)
+	}
+
+	// The listener was used up by the matching event.
+	before := atomic.LoadInt32(&calls)
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	if after := atomic.LoadInt32(&calls); after != before {
+		t.Fatalf("filter called after WaitFor returned")
+	}
+}
+
+// invokeTarget is an IEventTarget which invokes its listeners directly, without the event flow of EventTarget.
+type invokeTarget struct {
+	mtx       sync.Mutex
+	listeners map[string][]*events.EventListener
+}
+
+func (me *invokeTarget) AddEventListener(event string, listener *events.EventListener) {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	if me.listeners == nil {
+		me.listeners = make(map[string][]*events.EventListener)
+	}
+	me.listeners[event] = append(me.listeners[event], listener)
+}
+
+func (me *invokeTarget) RemoveEventListener(event string, listener *events.EventListener) {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	listeners := me.listeners[event]
+	for i, l := range listeners {
+		if l == listener {
+			me.listeners[event] = append(listeners[:i:i], listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+func (me *invokeTarget) DispatchEvent(e events.IEvent) events.EventResult {
+	me.mtx.Lock()
+	listeners := me.listeners[e.Type()]
+	me.mtx.Unlock()
+
+	for _, listener := range listeners {
+		listener.Invoke(e)
+	}
+	return events.NotCanceled
+}
+
+func (me *invokeTarget) count(event string) int {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	return len(me.listeners[event])
+}
+
+func TestWaitForOtherTarget(t *testing.T) {
+	target := new(invokeTarget)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			target.DispatchEvent(Event.New(Event.CHANGE, nil, Event.EventOptions{Cancelable: i%3 == 2}))
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	e, err := events.WaitFor(context.Background(), target, Event.CHANGE, func(e events.IEvent) bool {
+		return e.Cancelable()
+	})
+	close(done)
+	if err != nil || !e.Cancelable() {
+		t.Fatalf("unexpected result: %v, %v", e, err)
+	}
+	if n := target.count(Event.CHANGE); n != 0 {
+		t.Fatalf("%d listener[s] left after WaitFor returned", n)
+	}
+}
+
+func TestWaitForCanceled(t *testing.T) {
+	var calls int32
+	n := newNode("n", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := events.WaitFor(ctx, n, Event.CHANGE, func(e events.IEvent) bool {
+		atomic.AddInt32(&calls, 1)
+		return true
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	n.DispatchEvent(Event.New(Event.CHANGE, n))
+	if calls != 0 {
+		t.Fatalf("listener not removed after cancel")
+	}
+}
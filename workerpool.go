@@ -0,0 +1,96 @@
+package events
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+var (
+	// ErrWorkerPoolClosed is returned by the dispatches submitted to a closed WorkerPool.
+	ErrWorkerPoolClosed = errors.New("worker pool closed")
+
+	defaultWorkerPool     *WorkerPool
+	defaultWorkerPoolOnce sync.Once
+)
+
+// WorkerPool runs jobs on a bounded number of goroutines.
+// Jobs are queued without limit while all of the workers are busy, so that a job never blocks on submitting another one.
+type WorkerPool struct {
+	mtx    sync.Mutex
+	cond   sync.Cond
+	jobs   []func()
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// Init this class, and starts the workers.
+func (me *WorkerPool) Init(workers int) *WorkerPool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	me.cond.L = &me.mtx
+	me.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go me.work()
+	}
+	return me
+}
+
+// Submit queues the job, and returns false if the pool is closed.
+func (me *WorkerPool) Submit(job func()) bool {
+	me.mtx.Lock()
+	defer me.mtx.Unlock()
+
+	if me.closed {
+		return false
+	}
+	me.jobs = append(me.jobs, job)
+	me.cond.Signal()
+	return true
+}
+
+// Close stops the workers after the queued jobs are done, and waits for them.
+func (me *WorkerPool) Close() {
+	me.mtx.Lock()
+	me.closed = true
+	me.cond.Broadcast()
+	me.mtx.Unlock()
+
+	me.wg.Wait()
+}
+
+func (me *WorkerPool) work() {
+	defer me.wg.Done()
+
+	for {
+		me.mtx.Lock()
+		for len(me.jobs) == 0 && !me.closed {
+			me.cond.Wait()
+		}
+		if len(me.jobs) == 0 {
+			me.mtx.Unlock()
+			return
+		}
+		job := me.jobs[0]
+		me.jobs[0] = nil
+		me.jobs = me.jobs[1:]
+		me.mtx.Unlock()
+
+		job()
+	}
+}
+
+// NewWorkerPool creates a new WorkerPool with the number of workers, which defaults to GOMAXPROCS.
+func NewWorkerPool(workers int) *WorkerPool {
+	return new(WorkerPool).Init(workers)
+}
+
+// DefaultWorkerPool returns the pool shared by the targets which don't set their own, it is created on first use.
+func DefaultWorkerPool() *WorkerPool {
+	defaultWorkerPoolOnce.Do(func() {
+		defaultWorkerPool = NewWorkerPool(0)
+	})
+	return defaultWorkerPool
+}
@@ -0,0 +1,97 @@
+package events_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/oddengine/events"
+	Event "github.com/oddengine/events/event"
+)
+
+func TestDispatchEventAsyncOrder(t *testing.T) {
+	pool := events.NewWorkerPool(4)
+	defer pool.Close()
+
+	var want []string
+	for j := 0; j < 100; j++ {
+		want = append(want, fmt.Sprint(j))
+	}
+
+	// The targets are dispatched on concurrently, each one in order.
+	gots := make([][]string, 4)
+	handles := make([]*events.DispatchHandle, 4)
+	for i := range gots {
+		i := i
+		n := newNode(fmt.Sprint(i), nil)
+		n.SetWorkerPool(pool)
+		events.On(n, "*", func(e *Event.Event) {
+			gots[i] = append(gots[i], e.Type())
+		})
+		for _, typ := range want {
+			handles[i] = n.DispatchEventAsync(Event.New(typ, n))
+		}
+	}
+
+	for i, h := range handles {
+		h.Wait()
+		equal(t, gots[i], want...)
+	}
+}
+
+func TestDispatchEventAsyncResult(t *testing.T) {
+	errFailed := errors.New("failed")
+	pool := events.NewWorkerPool(1)
+	defer pool.Close()
+
+	n := newNode("n", nil)
+	n.SetWorkerPool(pool)
+	n.SetDefaultAction(Event.CLOSE, func(e events.IEvent) {})
+	events.OnWithError(n, Event.CLOSE, func(e *Event.Event) error {
+		return errFailed
+	})
+
+	h := n.DispatchEventAsync(Event.New(Event.CLOSE, n))
+	<-h.Done()
+	res, err := h.Wait()
+	if res != events.CanceledByDefaultEventHandler || !errors.Is(err, errFailed) {
+		t.Fatalf("res = %v, err = %v", res, err)
+	}
+}
+
+func TestDispatchEventAsyncPanic(t *testing.T) {
+	pool := events.NewWorkerPool(1)
+	defer pool.Close()
+
+	n := newNode("n", nil)
+	n.SetWorkerPool(pool)
+	n.SetPanicPolicy(events.RepanicOnPanic)
+	events.On(n, Event.CHANGE, func(e *Event.Event) {
+		panic("boom")
+	})
+
+	_, err := n.DispatchEventAsync(Event.New(Event.CHANGE, n)).Wait()
+	var perr *events.PanicError
+	if !errors.As(err, &perr) || perr.Value != "boom" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The queue of the target keeps running.
+	if _, err := n.DispatchEventAsync(Event.New(Event.OPEN, n)).Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDispatchEventAsyncClosedPool(t *testing.T) {
+	pool := events.NewWorkerPool(1)
+	pool.Close()
+
+	n := newNode("n", nil)
+	n.SetWorkerPool(pool)
+	if _, err := n.DispatchEventAsync(Event.New(Event.CHANGE, n)).Wait(); !errors.Is(err, events.ErrWorkerPoolClosed) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pool.Submit(func() {}) {
+		t.Fatalf("job submitted to a closed pool")
+	}
+}